@@ -0,0 +1,28 @@
+// Package api хранит контракт HTTP API — спецификацию OpenAPI, встроенную
+// в бинарник. Её отдаёт /openapi.yml и по ней проверяются запросы и ответы.
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Spec — openapi.yml в том виде, в каком он лежит в репозитории.
+//
+//go:embed openapi.yml
+var Spec []byte
+
+// Load разбирает встроенную спецификацию и проверяет, что она корректна.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("parse openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi spec: %w", err)
+	}
+	return doc, nil
+}
@@ -1,28 +1,278 @@
 package main
 
 import (
+	"avito/internal/auth"
+	"avito/internal/config"
 	"avito/internal/db"
+	"avito/internal/events"
+	grpcserver "avito/internal/grpc"
+	"avito/internal/health"
 	httphandler "avito/internal/http"
+	"avito/internal/logging"
+	"avito/internal/metrics"
+	"avito/internal/ratelimit"
+	pgrepo "avito/internal/repository/postgres"
+	"avito/internal/repository/traced"
+	"avito/internal/service"
+	"avito/internal/tracing"
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	cfg, printConfig, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if printConfig {
+		_ = cfg.Print(os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	if err := logging.Setup(cfg.Log.Level); err != nil {
+		fatal("setup logging", "error", err)
+	}
+	slog.Info("config loaded", "config", cfg.Redacted())
+	if !cfg.Auth.Enabled {
+		slog.Warn("authentication disabled: every caller has full access")
+	}
 
-	database := db.MustConnect(ctx)
+	// SIGTERM/SIGINT отменяют ctx и запускают остановку
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		fatal("setup tracing", "error", err)
+	}
+
+	database := db.MustConnect(ctx, cfg.DB)
 	if err := db.ApplyMigrations(ctx, database); err != nil {
-		log.Fatalf("apply migrations: %v", err)
+		fatal("apply migrations", "error", err)
+	}
+
+	// фоновые задачи живут до сигнала остановки; workers ждёт их завершения
+	var workers sync.WaitGroup
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// проверки готовности для /readyz
+	probe := health.NewProbe()
+	probe.Add("database", database.PingContext)
+	probe.Add("migrations", func(ctx context.Context) error {
+		pending, err := db.PendingMigrations(ctx, database)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	})
+
+	// метрики пула соединений и периодически обновляемые gauges
+	metrics.RegisterDB(database)
+	refreshInterval := time.Duration(cfg.Metrics.RefreshInterval)
+	refresherBeat := health.NewHeartbeat(3 * refreshInterval)
+	probe.Add("gauge_refresher", refresherBeat.Check)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		metrics.RunGaugeRefresher(workersCtx, pgrepo.NewPRRepo(database), refreshInterval, refresherBeat.Beat)
+	}()
+
+	prOpts := []service.PullRequestOption{service.WithReviewerCount(cfg.Reviewers.Count)}
+	if cfg.Reviewers.Strategy == "pr_hash" {
+		prOpts = append(prOpts, service.WithDeterministicAssignment())
+	}
+
+	authOpts := []service.AuthOption{service.WithBootstrapAdminKey(cfg.Auth.BootstrapAdminKey)}
+	if jwtCfg := cfg.Auth.JWT; jwtCfg.Enabled() {
+		jwks := auth.NewJWKS(jwtCfg.JWKSSource(), time.Duration(jwtCfg.RefreshInterval))
+		if err := jwks.Load(ctx); err != nil {
+			fatal("load jwks", "source", jwtCfg.JWKSSource(), "error", err)
+		}
+		authOpts = append(authOpts, service.WithJWTVerifier(auth.NewJWTVerifier(jwks, jwtCfg.Issuer, jwtCfg.Audience)))
+		slog.Info("jwt authentication enabled", "jwks", jwtCfg.JWKSSource())
+	}
+
+	// лимиты общие для HTTP и gRPC
+	var rateLimits ratelimit.Groups
+	if rl := cfg.RateLimit; rl.Enabled {
+		rateLimits = ratelimit.NewGroups(map[string]ratelimit.Limit{
+			ratelimit.GroupIP:           ratelimit.Limit(rl.IP),
+			ratelimit.GroupDefault:      ratelimit.Limit(rl.Default),
+			ratelimit.GroupPullRequests: ratelimit.Limit(rl.PullRequests),
+			ratelimit.GroupTeams:        ratelimit.Limit(rl.Teams),
+			ratelimit.GroupStats:        ratelimit.Limit(rl.Stats),
+		})
+	}
+
+	// журнал событий PR: каждая реплика слушает pr_events и раздаёт новые
+	// события подписчикам /events/stream, старые события удаляются
+	eventRepo := traced.NewEventRepo(pgrepo.NewEventRepo(database))
+	broker := events.NewBroker(cfg.Events.BufferSize)
+	listenerBeat := health.NewHeartbeat(3 * events.PingInterval())
+	probe.Add("event_listener", listenerBeat.Check)
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		events.NewListener(database, eventRepo, broker).Run(workersCtx, listenerBeat.Beat)
+	}()
+	go func() {
+		defer workers.Done()
+		events.RunPruner(workersCtx, eventRepo, time.Duration(cfg.Events.Retention), time.Hour)
+	}()
+
+	// репозитории Postgres, обёрнутые спанами трассировки; сервисы общие
+	// для HTTP и gRPC
+	svcs := service.NewServices(service.Repositories{
+		Teams:        traced.NewTeamRepo(pgrepo.NewTeamRepo(database)),
+		Users:        traced.NewUserRepo(pgrepo.NewUserRepo(database)),
+		PullRequests: traced.NewPRRepo(pgrepo.NewPRRepo(database)),
+		APIKeys:      traced.NewAPIKeyRepo(pgrepo.NewAPIKeyRepo(database)),
+		Orgs:         traced.NewOrgRepo(pgrepo.NewOrgRepo(database)),
+		Events:       eventRepo,
+		Tx:           traced.NewTransactor(pgrepo.NewTxManager(database)),
+	}, broker, prOpts, authOpts)
+
+	router := httphandler.NewRouter(svcs, httphandler.RouterOptions{
+		Probe:             probe,
+		AuthEnabled:       cfg.Auth.Enabled,
+		RateLimits:        rateLimits,
+		MaxBodyBytes:      int64(cfg.HTTP.MaxBodyBytes),
+		ValidateRequests:  cfg.HTTP.ValidateRequests,
+		ValidateResponses: cfg.HTTP.ValidateResponses,
+		EventsHeartbeat:   time.Duration(cfg.Events.Heartbeat),
+		WebSocket: httphandler.InboxOptions{
+			MaxConnections: cfg.WebSocket.MaxConnections,
+			BufferSize:     cfg.WebSocket.BufferSize,
+			PingInterval:   time.Duration(cfg.WebSocket.PingInterval),
+			WriteTimeout:   time.Duration(cfg.WebSocket.WriteTimeout),
+		},
+	})
+
+	srv := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: time.Duration(cfg.HTTP.ReadHeaderTimeout),
+		ReadTimeout:       time.Duration(cfg.HTTP.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.HTTP.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.HTTP.IdleTimeout),
+	}
+	shutdownTimeout := time.Duration(cfg.HTTP.ShutdownTimeout)
+	// открытые потоки событий сами не завершаются: закрываем подписки,
+	// чтобы Shutdown не ждал их до таймаута
+	srv.RegisterOnShutdown(broker.Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// gRPC API на отдельном порту; пустой адрес отключает его
+	var grpcSrv *grpcserver.Server
+	grpcErr := make(chan error, 1)
+	if cfg.GRPC.Addr != "" {
+		lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			fatal("grpc listen", "addr", cfg.GRPC.Addr, "error", err)
+		}
+		grpcSrv = grpcserver.NewServer(svcs, grpcserver.Options{
+			AuthEnabled:     cfg.Auth.Enabled,
+			RateLimits:      rateLimits,
+			MaxRecvMsgBytes: cfg.HTTP.MaxBodyBytes,
+		})
+		go func() {
+			slog.Info("grpc listening", "addr", lis.Addr().String())
+			grpcErr <- grpcSrv.Serve(lis)
+		}()
 	}
 
-	router := httphandler.NewRouter(database)
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fatal("http server", "error", err)
+		}
+	case err := <-grpcErr:
+		fatal("grpc server", "error", err)
+	case <-ctx.Done():
+		slog.Info("shutdown started", "timeout", shutdownTimeout.String())
+	}
+	stop() // повторный сигнал завершает процесс сразу
 
-	addr := ":8080"
-	log.Printf("listening on %s\n", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
-		log.Fatal(err)
+	// /readyz отвечает 503, пока балансировщик не исключит инстанс
+	probe.SetDraining()
+	if grpcSrv != nil {
+		grpcSrv.SetDraining()
 	}
+	time.Sleep(time.Duration(cfg.HTTP.DrainDelay))
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	// перестаём принимать соединения и ждём завершения текущих запросов
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http server shutdown", "error", err)
+		_ = srv.Close()
+	}
+	if grpcSrv != nil {
+		if err := grpcSrv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("grpc server shutdown", "error", err)
+		}
+	}
+
+	stopWorkers()
+	if err := waitGroup(shutdownCtx, &workers); err != nil {
+		slog.Error("background workers did not stop in time", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("shutdown tracing", "error", err)
+	}
+	if err := database.Close(); err != nil {
+		slog.Error("close db", "error", err)
+	}
+	slog.Info("shutdown complete")
+}
+
+// waitGroup ждёт wg, но не дольше, чем живёт ctx.
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
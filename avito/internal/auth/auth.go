@@ -0,0 +1,121 @@
+// Package auth хранит аутентифицированного вызывающего в контексте и
+// описывает правила доступа, общие для всех транспортов.
+package auth
+
+import (
+	"avito/internal/domain"
+	"context"
+	"slices"
+)
+
+type principalKey struct{}
+
+// WithPrincipal кладёт вызывающего в контекст.
+func WithPrincipal(ctx context.Context, p *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает вызывающего. ok == false означает, что
+// аутентификация отключена (локальный запуск, тестовый роутер).
+func FromContext(ctx context.Context) (p *domain.Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(*domain.Principal)
+	return p, ok
+}
+
+// Actor возвращает, от чьего имени выполняется действие, для записи в
+// журналы: user_id, если вызывающий связан с пользователем, иначе его
+// Subject. Без аутентификации — пустая строка.
+func Actor(ctx context.Context) string {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if p.UserID != "" {
+		return p.UserID
+	}
+	return p.Subject
+}
+
+// HasRole сообщает, есть ли у вызывающего одна из ролей roles.
+// Без аутентификации разрешено всё.
+func HasRole(ctx context.Context, roles ...domain.Role) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	return slices.Contains(roles, p.Role)
+}
+
+// CanManageTeam разрешает управление командой teamID (см. domain.Team.ID)
+// администратору и лиду этой команды.
+func CanManageTeam(ctx context.Context, teamID int64) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	switch p.Role {
+	case domain.RoleAdmin:
+		return true
+	case domain.RoleTeamLead:
+		return p.TeamID != 0 && p.TeamID == teamID
+	default:
+		return false
+	}
+}
+
+// CanSetUserActive разрешает менять is_active пользователя userID из команды
+// userTeamID администратору, лиду этой команды и самому пользователю.
+func CanSetUserActive(ctx context.Context, userID string, userTeamID int64) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	switch p.Role {
+	case domain.RoleAdmin:
+		return true
+	case domain.RoleTeamLead:
+		return (userTeamID != 0 && p.TeamID == userTeamID) || p.UserID == userID
+	case domain.RoleMember:
+		return p.UserID == userID
+	default:
+		return false
+	}
+}
+
+// CanReadInbox разрешает читать входящие ревью пользователя userID из
+// команды userTeamID самому пользователю, лиду этой команды и администратору.
+func CanReadInbox(ctx context.Context, userID string, userTeamID int64) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	switch {
+	case p.Role == domain.RoleAdmin, p.UserID != "" && p.UserID == userID:
+		return true
+	case p.Role == domain.RoleTeamLead:
+		return userTeamID != 0 && p.TeamID == userTeamID
+	default:
+		return false
+	}
+}
+
+// IsPlatformAdmin сообщает, что вызывающий — администратор без привязки
+// к организации и может создавать организации и работать в любой из них.
+// Без аутентификации разрешено всё.
+func IsPlatformAdmin(ctx context.Context) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	return p.Role == domain.RoleAdmin && p.OrgID == ""
+}
+
+// CanAccessOrg разрешает работу с организацией orgID её участникам
+// и администратору платформы.
+func CanAccessOrg(ctx context.Context, orgID string) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	return p.OrgID == "" || p.OrgID == orgID
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"avito/internal/logging"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefetchInterval ограничивает повторные загрузки JWKS при токенах
+// с неизвестным kid, чтобы мусорные токены не превращались в запросы к IdP.
+const minRefetchInterval = 10 * time.Second
+
+// JWKS — набор открытых ключей из файла или по URL с кэшем. Ключи
+// перечитываются раз в refresh или раньше, если встретился неизвестный kid,
+// но не чаще minRefetchInterval. При ошибке загрузки продолжают действовать
+// ранее загруженные ключи.
+type JWKS struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	// flight объединяет одновременные перезагрузки в одну
+	flight singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	loadedAt    time.Time
+	attemptedAt time.Time
+}
+
+// NewJWKS создаёт набор ключей; source — путь к файлу или http(s)-URL.
+func NewJWKS(source string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Load загружает ключи. Вызывается при старте, чтобы ошибка в конфигурации
+// обнаружилась сразу, а не на первом запросе.
+func (j *JWKS) Load(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = time.Now()
+	j.mu.Unlock()
+	return j.load(ctx)
+}
+
+// Key возвращает ключ по kid. Пустой kid допустим, если в наборе один ключ.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.RLock()
+	key, ok := j.lookup(kid)
+	stale := time.Since(j.loadedAt) > j.refresh
+	j.mu.RUnlock()
+	if ok && !stale {
+		return key, nil
+	}
+
+	j.refetch(ctx)
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// refetch перечитывает ключи, если с прошлой попытки прошло не меньше
+// minRefetchInterval. Загрузка идёт без блокировки: проверка токенов с
+// известными kid её не ждёт, а одновременные промахи ждут одну загрузку.
+func (j *JWKS) refetch(ctx context.Context) {
+	_, err, _ := j.flight.Do(j.source, func() (any, error) {
+		j.mu.Lock()
+		if time.Since(j.attemptedAt) < minRefetchInterval {
+			j.mu.Unlock()
+			return nil, nil
+		}
+		j.attemptedAt = time.Now()
+		j.mu.Unlock()
+
+		// загрузку разделяют несколько запросов: отмена первого из них
+		// не должна прерывать её для остальных, её ограничивает j.client
+		return nil, j.load(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "jwks refresh failed", "source", j.source, "error", err.Error())
+	}
+}
+
+func (j *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, k := range j.keys {
+			return k, true
+		}
+	}
+	k, ok := j.keys[kid]
+	return k, ok
+}
+
+// load читает и разбирает набор без блокировки и подменяет ключи под ней.
+func (j *JWKS) load(ctx context.Context) error {
+	data, err := j.read(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parse jwks %s: %w", j.source, err)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.loadedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks %s: status %d", j.source, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS разбирает RSA- и EC P-256-ключи для подписи; ключи других
+// типов и ключи шифрования пропускаются.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsa()
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			key, err = k.ecdsa()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable RSA or P-256 signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode e: %w", err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+func (k jwk) ecdsa() (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("decode x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("decode y: %w", err)
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 coordinates")
+	}
+	point := append(append([]byte{4}, x...), y...)
+	return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+}
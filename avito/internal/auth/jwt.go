@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"avito/internal/domain"
+	"avito/internal/tenant"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtLeeway — допустимое расхождение часов с выпускающей стороной.
+const jwtLeeway = 30 * time.Second
+
+// rolePriority — при нескольких ролях в токене действует самая сильная.
+var rolePriority = []domain.Role{domain.RoleAdmin, domain.RoleTeamLead, domain.RoleMember, domain.RoleBot}
+
+// JWTVerifier проверяет токены RS256/ES256, выпущенные внутренними
+// сервисами, по ключам из JWKS.
+type JWTVerifier struct {
+	keys     *JWKS
+	issuer   string
+	audience string
+}
+
+// NewJWTVerifier создаёт проверку; пустые issuer и audience не проверяются.
+func NewJWTVerifier(keys *JWKS, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{keys: keys, issuer: issuer, audience: audience}
+}
+
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Org   string   `json:"org"`
+	Team  string   `json:"team"`
+	Roles []string `json:"roles"`
+}
+
+// LooksLikeJWT отличает JWT (три части через точку) от API-ключа.
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify проверяет подпись и срок действия токена и переводит claims
+// sub, org, team и roles в вызывающего. Без roles вызывающий считается
+// member, без org — участником организации по умолчанию.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	var claims jwtClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no sub claim")
+	}
+
+	role := domain.RoleMember
+	if len(claims.Roles) > 0 {
+		var ok bool
+		if role, ok = strongestRole(claims.Roles); !ok {
+			return nil, errors.New("token has no known role")
+		}
+	}
+	if role == domain.RoleTeamLead && claims.Team == "" {
+		return nil, errors.New("team-lead token has no team claim")
+	}
+
+	org := claims.Org
+	if org == "" {
+		org = tenant.Default
+	}
+	if !tenant.ValidID(org) {
+		return nil, errors.New("token has invalid org claim")
+	}
+
+	return &domain.Principal{
+		Subject:  "jwt:" + claims.Subject,
+		Name:     claims.Subject,
+		Role:     role,
+		OrgID:    org,
+		UserID:   claims.Subject,
+		TeamName: claims.Team,
+	}, nil
+}
+
+func strongestRole(roles []string) (domain.Role, bool) {
+	for _, r := range rolePriority {
+		for _, claimed := range roles {
+			if domain.Role(claimed) == r {
+				return r, true
+			}
+		}
+	}
+	return "", false
+}
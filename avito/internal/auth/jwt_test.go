@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"avito/internal/domain"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://idp.test"
+	testAudience = "pr-reviewer"
+)
+
+// testKeys — ключи, которыми тесты подписывают токены, и JWKS с их
+// открытыми частями.
+type testKeys struct {
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	jwks []byte
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	point, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("encode ec key: %v", err)
+	}
+	jwks, err := json.Marshal(map[string]any{"keys": []jwk{
+		{
+			Kty: "RSA", Kid: "rsa-1", Use: "sig",
+			N: b64(rsaKey.N.Bytes()),
+			E: b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		// несжатая точка: 0x04 || X || Y
+		{Kty: "EC", Kid: "ec-1", Crv: "P-256", X: b64(point[1:33]), Y: b64(point[33:])},
+	}})
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	return &testKeys{rsa: rsaKey, ec: ecKey, jwks: jwks}
+}
+
+// serve отдаёт JWKS по HTTP и считает запросы к нему.
+func (k *testKeys) serve(t *testing.T) (url string, hits *atomic.Int64) {
+	t.Helper()
+	hits = new(atomic.Int64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write(k.jwks)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, hits
+}
+
+func (k *testKeys) verifier(t *testing.T) *JWTVerifier {
+	t.Helper()
+	url, _ := k.serve(t)
+	jwks := NewJWKS(url, time.Hour)
+	if err := jwks.Load(context.Background()); err != nil {
+		t.Fatalf("load jwks: %v", err)
+	}
+	return NewJWTVerifier(jwks, testIssuer, testAudience)
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "u1",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"org":   "acme",
+		"team":  "backend",
+		"roles": []string{"member", "team-lead"},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key any) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return s
+}
+
+func TestVerifyAcceptsRS256AndES256(t *testing.T) {
+	keys := newTestKeys(t)
+	v := keys.verifier(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"RS256", sign(t, jwt.SigningMethodRS256, "rsa-1", validClaims(), keys.rsa)},
+		{"ES256", sign(t, jwt.SigningMethodES256, "ec-1", validClaims(), keys.ec)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			want := domain.Principal{
+				Subject:  "jwt:u1",
+				Name:     "u1",
+				Role:     domain.RoleTeamLead,
+				OrgID:    "acme",
+				UserID:   "u1",
+				TeamName: "backend",
+			}
+			if *p != want {
+				t.Fatalf("principal = %+v, want %+v", *p, want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	v := keys.verifier(t)
+
+	with := func(key string, value any) jwt.MapClaims {
+		c := validClaims()
+		c[key] = value
+		return c
+	}
+	// HS256 с открытым ключом RSA в роли секрета — классическая подмена
+	// алгоритма; открытая часть ключа известна любому
+	rsaPub, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal rsa public key: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"unknown kid", sign(t, jwt.SigningMethodRS256, "rsa-2", validClaims(), keys.rsa)},
+		{"expired", sign(t, jwt.SigningMethodRS256, "rsa-1", with("exp", time.Now().Add(-time.Hour).Unix()), keys.rsa)},
+		{"no exp", func() string {
+			c := validClaims()
+			delete(c, "exp")
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", c, keys.rsa)
+		}()},
+		{"wrong issuer", sign(t, jwt.SigningMethodRS256, "rsa-1", with("iss", "https://evil.test"), keys.rsa)},
+		{"wrong audience", sign(t, jwt.SigningMethodRS256, "rsa-1", with("aud", "other-service"), keys.rsa)},
+		{"alg none", sign(t, jwt.SigningMethodNone, "rsa-1", validClaims(), jwt.UnsafeAllowNoneSignatureType)},
+		{"alg HS256", sign(t, jwt.SigningMethodHS256, "rsa-1", validClaims(), rsaPub)},
+		{"signed by another key", func() string {
+			other, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				t.Fatalf("generate rsa key: %v", err)
+			}
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", validClaims(), other)
+		}()},
+		{"team-lead without team", sign(t, jwt.SigningMethodES256, "ec-1", with("team", ""), keys.ec)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := v.Verify(context.Background(), tt.token); err == nil {
+				t.Fatalf("Verify accepted the token: %+v", *p)
+			}
+		})
+	}
+}
+
+func TestJWKSRefetchesUnknownKidOnce(t *testing.T) {
+	keys := newTestKeys(t)
+	url, hits := keys.serve(t)
+	jwks := NewJWKS(url, time.Hour)
+	if err := jwks.Load(context.Background()); err != nil {
+		t.Fatalf("load jwks: %v", err)
+	}
+
+	// как будто с загрузки прошло больше minRefetchInterval: поток токенов
+	// с неизвестным kid даёт одну загрузку, сколько бы их ни пришло
+	jwks.attemptedAt = time.Time{}
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			if _, err := jwks.Key(context.Background(), "missing"); err == nil {
+				t.Error("Key returned a key for an unknown kid")
+			}
+		})
+	}
+	wg.Wait()
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("jwks fetched %d times, want 2 (startup and one refetch)", n)
+	}
+
+	// следующий промах в пределах minRefetchInterval к IdP не ходит
+	if _, err := jwks.Key(context.Background(), "missing"); err == nil {
+		t.Fatal("Key returned a key for an unknown kid")
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("jwks fetched %d times after a repeated miss, want 2", n)
+	}
+
+	// известные ключи при этом продолжают находиться
+	if _, err := jwks.Key(context.Background(), "ec-1"); err != nil {
+		t.Fatalf("Key(ec-1): %v", err)
+	}
+}
@@ -0,0 +1,421 @@
+// Package config собирает настройки сервиса из файла (YAML или TOML),
+// переменных окружения и флагов командной строки. Более поздний источник
+// перекрывает более ранний: значения по умолчанию < файл < env < флаги.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	HTTP      HTTP      `yaml:"http" toml:"http"`
+	GRPC      GRPC      `yaml:"grpc" toml:"grpc"`
+	DB        DB        `yaml:"db" toml:"db"`
+	Log       Log       `yaml:"log" toml:"log"`
+	Metrics   Metrics   `yaml:"metrics" toml:"metrics"`
+	Reviewers Reviewers `yaml:"reviewers" toml:"reviewers"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	RateLimit RateLimit `yaml:"rate_limit" toml:"rate_limit"`
+	Events    Events    `yaml:"events" toml:"events"`
+	WebSocket WebSocket `yaml:"websocket" toml:"websocket"`
+}
+
+type HTTP struct {
+	Addr              string   `yaml:"addr" toml:"addr"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// DrainDelay — сколько после сигнала остановки сервис ещё принимает
+	// запросы, отвечая 503 на /readyz, чтобы балансировщик успел его исключить.
+	DrainDelay Duration `yaml:"drain_delay" toml:"drain_delay"`
+	// MaxBodyBytes — предельный размер тела запроса (больше — 413) и входящего
+	// сообщения gRPC.
+	MaxBodyBytes int `yaml:"max_body_bytes" toml:"max_body_bytes"`
+	// ValidateRequests отклоняет запросы, не соответствующие api/openapi.yml.
+	ValidateRequests bool `yaml:"validate_requests" toml:"validate_requests"`
+	// ValidateResponses сверяет ответы со спецификацией и пишет расхождения
+	// в лог. Ответ клиенту при этом не меняется.
+	ValidateResponses bool `yaml:"validate_responses" toml:"validate_responses"`
+}
+
+// GRPC — gRPC API (api/reviewer/v1). Таймауты и остановка общие с HTTP.
+type GRPC struct {
+	// Addr — адрес gRPC-сервера; пустой отключает его.
+	Addr string `yaml:"addr" toml:"addr"`
+}
+
+type DB struct {
+	// URL содержит пароль и при печати конфигурации скрывается.
+	URL                  string   `yaml:"url" toml:"url"`
+	MaxOpenConns         int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns         int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime      Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnectAttempts      int      `yaml:"connect_attempts" toml:"connect_attempts"`
+	ConnectRetryInterval Duration `yaml:"connect_retry_interval" toml:"connect_retry_interval"`
+}
+
+type Log struct {
+	Level string `yaml:"level" toml:"level"`
+}
+
+type Metrics struct {
+	RefreshInterval Duration `yaml:"refresh_interval" toml:"refresh_interval"`
+}
+
+type Reviewers struct {
+	// Strategy — random или pr_hash.
+	Strategy string `yaml:"strategy" toml:"strategy"`
+	// Count — сколько ревьюверов назначается на новый PR.
+	Count int `yaml:"count" toml:"count"`
+}
+
+type Auth struct {
+	// Enabled выключает проверку ключей для локального запуска.
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// BootstrapAdminKey — ключ администратора, который не хранится в БД и
+	// нужен, чтобы выпустить первые ключи. При печати конфигурации скрывается.
+	BootstrapAdminKey string `yaml:"bootstrap_admin_key" toml:"bootstrap_admin_key"`
+	JWT               JWT    `yaml:"jwt" toml:"jwt"`
+}
+
+// JWT включается, если задан JWKSFile или JWKSURL.
+type JWT struct {
+	JWKSFile        string   `yaml:"jwks_file" toml:"jwks_file"`
+	JWKSURL         string   `yaml:"jwks_url" toml:"jwks_url"`
+	RefreshInterval Duration `yaml:"refresh_interval" toml:"refresh_interval"`
+	// Issuer и Audience проверяются, только если заданы.
+	Issuer   string `yaml:"issuer" toml:"issuer"`
+	Audience string `yaml:"audience" toml:"audience"`
+}
+
+// Enabled сообщает, настроен ли вход по JWT.
+func (j JWT) Enabled() bool {
+	return j.JWKSFile != "" || j.JWKSURL != ""
+}
+
+// JWKSSource возвращает путь к файлу или URL набора ключей.
+func (j JWT) JWKSSource() string {
+	if j.JWKSFile != "" {
+		return j.JWKSFile
+	}
+	return j.JWKSURL
+}
+
+// RateLimit задаёт token bucket на клиента (ключ API, субъект JWT или IP)
+// отдельно для каждой группы маршрутов.
+type RateLimit struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// IP — IP-адрес до аутентификации, в том числе запросы без ключа или
+	// с неверным ключом; должен быть не строже остальных групп.
+	IP RateLimitGroup `yaml:"ip" toml:"ip"`
+	// Default — все маршруты API, не попавшие в другие группы.
+	Default      RateLimitGroup `yaml:"default" toml:"default"`
+	PullRequests RateLimitGroup `yaml:"pull_requests" toml:"pull_requests"`
+	Teams        RateLimitGroup `yaml:"teams" toml:"teams"`
+	Stats        RateLimitGroup `yaml:"stats" toml:"stats"`
+}
+
+type RateLimitGroup struct {
+	// RPS — скорость пополнения корзины, запросов в секунду.
+	RPS float64 `yaml:"rps" toml:"rps"`
+	// Burst — ёмкость корзины: сколько запросов можно сделать подряд.
+	Burst int `yaml:"burst" toml:"burst"`
+}
+
+// Events — поток событий PR (/events/stream) и их журнал.
+type Events struct {
+	// Heartbeat — как часто в пустой поток пишется комментарий, чтобы
+	// прокси и клиент не закрыли соединение по простою.
+	Heartbeat Duration `yaml:"heartbeat" toml:"heartbeat"`
+	// BufferSize — сколько событий подписчик может не забрать, прежде
+	// чем поток будет закрыт и клиенту придётся переподключиться.
+	BufferSize int `yaml:"buffer_size" toml:"buffer_size"`
+	// Retention — сколько хранится журнал, по которому клиент догоняет
+	// пропущенное после переподключения.
+	Retention Duration `yaml:"retention" toml:"retention"`
+}
+
+// WebSocket — подписки на входящие ревью (/ws/inbox).
+type WebSocket struct {
+	// MaxConnections — сколько соединений одновременно держит процесс;
+	// сверх него рукопожатие отклоняется с 503.
+	MaxConnections int `yaml:"max_connections" toml:"max_connections"`
+	// BufferSize — сколько событий соединение может не отправить, прежде
+	// чем оно будет закрыто как медленное.
+	BufferSize int `yaml:"buffer_size" toml:"buffer_size"`
+	// PingInterval — как часто сервер пингует клиента; не ответивший
+	// за WriteTimeout клиент отключается.
+	PingInterval Duration `yaml:"ping_interval" toml:"ping_interval"`
+	// WriteTimeout ограничивает отправку одного сообщения и ожидание понга.
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout"`
+}
+
+// Duration — time.Duration, которая в файле записывается строкой вида 30s.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Addr:              ":8080",
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			ReadTimeout:       Duration(15 * time.Second),
+			WriteTimeout:      Duration(60 * time.Second),
+			IdleTimeout:       Duration(120 * time.Second),
+			ShutdownTimeout:   Duration(30 * time.Second),
+			DrainDelay:        Duration(5 * time.Second),
+			MaxBodyBytes:      1 << 20,
+		},
+		GRPC: GRPC{Addr: ":9090"},
+		DB: DB{
+			MaxOpenConns:         10,
+			MaxIdleConns:         5,
+			ConnMaxLifetime:      Duration(time.Hour),
+			ConnectAttempts:      30,
+			ConnectRetryInterval: Duration(time.Second),
+		},
+		Log: Log{Level: "info"},
+		Metrics: Metrics{
+			RefreshInterval: Duration(15 * time.Second),
+		},
+		Reviewers: Reviewers{
+			Strategy: "random",
+			Count:    2,
+		},
+		Auth: Auth{
+			Enabled: true,
+			JWT:     JWT{RefreshInterval: Duration(5 * time.Minute)},
+		},
+		RateLimit: RateLimit{
+			Enabled:      true,
+			IP:           RateLimitGroup{RPS: 50, Burst: 100},
+			Default:      RateLimitGroup{RPS: 20, Burst: 40},
+			PullRequests: RateLimitGroup{RPS: 5, Burst: 10},
+			Teams:        RateLimitGroup{RPS: 2, Burst: 10},
+			Stats:        RateLimitGroup{RPS: 2, Burst: 5},
+		},
+		Events: Events{
+			Heartbeat:  Duration(15 * time.Second),
+			BufferSize: 256,
+			Retention:  Duration(7 * 24 * time.Hour),
+		},
+		WebSocket: WebSocket{
+			MaxConnections: 1000,
+			BufferSize:     64,
+			PingInterval:   Duration(30 * time.Second),
+			WriteTimeout:   Duration(10 * time.Second),
+		},
+	}
+}
+
+// Load собирает конфигурацию из args (обычно os.Args[1:]), окружения
+// и файла, путь к которому задаётся флагом --config или CONFIG_FILE.
+// printConfig сообщает, передан ли флаг --print-config.
+func Load(args []string) (cfg Config, printConfig bool, err error) {
+	cfg = Default()
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet("pr-reviewer-service", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML or TOML config file (env CONFIG_FILE)")
+	fs.BoolVar(&printConfig, "print-config", false, "print effective config with secrets redacted and exit")
+
+	// флаги разбираются первыми, чтобы узнать путь к файлу, а применяются
+	// последними — поверх файла и окружения
+	fromFlags := make(map[string]string)
+	for _, s := range settings {
+		fs.Func(s.flag, s.usage+" (env "+s.env+")", func(v string) error {
+			fromFlags[s.flag] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, false, err
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return cfg, false, err
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(v); err != nil {
+				return cfg, false, fmt.Errorf("env %s: %w", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if v, ok := fromFlags[s.flag]; ok {
+			if err := s.set(v); err != nil {
+				return cfg, false, fmt.Errorf("flag --%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	return cfg, printConfig, cfg.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate проверяет, что настройки согласованы, и возвращает все
+// найденные ошибки разом.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Addr != "", "http.addr must not be empty")
+	check(c.GRPC.Addr != c.HTTP.Addr, "grpc.addr must differ from http.addr")
+	check(c.HTTP.ReadHeaderTimeout > 0, "http.read_header_timeout must be positive")
+	check(c.HTTP.ReadTimeout > 0, "http.read_timeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http.write_timeout must be positive")
+	check(c.HTTP.IdleTimeout > 0, "http.idle_timeout must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	check(c.HTTP.DrainDelay >= 0, "http.drain_delay must not be negative")
+	check(c.HTTP.MaxBodyBytes > 0, "http.max_body_bytes must be positive")
+
+	check(c.DB.URL != "", "db.url must be set (env DATABASE_URL)")
+	check(c.DB.MaxOpenConns > 0, "db.max_open_conns must be positive")
+	check(c.DB.MaxIdleConns >= 0 && c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns must be between 0 and db.max_open_conns")
+	check(c.DB.ConnMaxLifetime > 0, "db.conn_max_lifetime must be positive")
+	check(c.DB.ConnectAttempts > 0, "db.connect_attempts must be positive")
+	check(c.DB.ConnectRetryInterval > 0, "db.connect_retry_interval must be positive")
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level must be one of: debug, info, warn, error")
+	}
+
+	check(c.Metrics.RefreshInterval > 0, "metrics.refresh_interval must be positive")
+
+	switch c.Reviewers.Strategy {
+	case "random", "pr_hash":
+	default:
+		check(false, "reviewers.strategy must be random or pr_hash")
+	}
+	check(c.Reviewers.Count > 0, "reviewers.count must be positive")
+
+	// без ключа администратора и JWT нельзя выпустить ни одного ключа
+	check(!c.Auth.Enabled || c.Auth.BootstrapAdminKey != "" || c.Auth.JWT.Enabled(),
+		"auth.enabled requires auth.bootstrap_admin_key or auth.jwt.jwks_file/jwks_url")
+	check(c.Auth.BootstrapAdminKey == "" || len(c.Auth.BootstrapAdminKey) >= 16,
+		"auth.bootstrap_admin_key must be at least 16 characters")
+	check(c.Auth.JWT.JWKSFile == "" || c.Auth.JWT.JWKSURL == "",
+		"auth.jwt.jwks_file and auth.jwt.jwks_url are mutually exclusive")
+	check(c.Auth.JWT.JWKSURL == "" || strings.HasPrefix(c.Auth.JWT.JWKSURL, "http://") ||
+		strings.HasPrefix(c.Auth.JWT.JWKSURL, "https://"), "auth.jwt.jwks_url must be an http(s) URL")
+	check(c.Auth.JWT.RefreshInterval > 0, "auth.jwt.refresh_interval must be positive")
+
+	for _, g := range []struct {
+		name string
+		RateLimitGroup
+	}{
+		{"ip", c.RateLimit.IP},
+		{"default", c.RateLimit.Default},
+		{"pull_requests", c.RateLimit.PullRequests},
+		{"teams", c.RateLimit.Teams},
+		{"stats", c.RateLimit.Stats},
+	} {
+		check(g.RPS > 0 && g.Burst > 0, "rate_limit.%s.rps and rate_limit.%s.burst must be positive", g.name, g.name)
+	}
+
+	check(c.Events.Heartbeat > 0, "events.heartbeat must be positive")
+	check(c.Events.BufferSize > 0, "events.buffer_size must be positive")
+	check(c.Events.Retention > 0, "events.retention must be positive")
+
+	check(c.WebSocket.MaxConnections > 0, "websocket.max_connections must be positive")
+	check(c.WebSocket.BufferSize > 0, "websocket.buffer_size must be positive")
+	check(c.WebSocket.PingInterval > 0, "websocket.ping_interval must be positive")
+	check(c.WebSocket.WriteTimeout > 0, "websocket.write_timeout must be positive")
+
+	return errors.Join(errs...)
+}
+
+// Redacted возвращает копию конфигурации, безопасную для вывода в лог.
+func (c Config) Redacted() Config {
+	c.DB.URL = redactURL(c.DB.URL)
+	if c.Auth.BootstrapAdminKey != "" {
+		c.Auth.BootstrapAdminKey = "REDACTED"
+	}
+	return c
+}
+
+// Print пишет эффективную конфигурацию в YAML со скрытыми секретами.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// redactURL скрывает пароль в URL подключения; строку, которую не удалось
+// разобрать как URL (например, DSN вида key=value), скрывает целиком.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return "REDACTED"
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	q := u.Query()
+	if q.Has("password") {
+		q.Set("password", "REDACTED")
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// setting связывает поле Config с переменной окружения и флагом.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(string) error
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		stringSetting("HTTP_ADDR", "http-addr", "HTTP listen address", &c.HTTP.Addr),
+		durationSetting("HTTP_READ_HEADER_TIMEOUT", "http-read-header-timeout", "HTTP read header timeout", &c.HTTP.ReadHeaderTimeout),
+		durationSetting("HTTP_READ_TIMEOUT", "http-read-timeout", "HTTP read timeout", &c.HTTP.ReadTimeout),
+		durationSetting("HTTP_WRITE_TIMEOUT", "http-write-timeout", "HTTP write timeout", &c.HTTP.WriteTimeout),
+		durationSetting("HTTP_IDLE_TIMEOUT", "http-idle-timeout", "HTTP keep-alive idle timeout", &c.HTTP.IdleTimeout),
+		durationSetting("SHUTDOWN_TIMEOUT", "shutdown-timeout", "graceful shutdown deadline", &c.HTTP.ShutdownTimeout),
+		durationSetting("SHUTDOWN_DRAIN_DELAY", "shutdown-drain-delay", "delay between failing /readyz and stopping the server", &c.HTTP.DrainDelay),
+		intSetting("HTTP_MAX_BODY_BYTES", "http-max-body-bytes", "max request body size in bytes", &c.HTTP.MaxBodyBytes),
+		boolSetting("HTTP_VALIDATE_REQUESTS", "http-validate-requests", "reject requests that do not match the OpenAPI spec", &c.HTTP.ValidateRequests),
+		boolSetting("HTTP_VALIDATE_RESPONSES", "http-validate-responses", "log responses that do not match the OpenAPI spec", &c.HTTP.ValidateResponses),
+
+		stringSetting("GRPC_ADDR", "grpc-addr", "gRPC listen address; empty disables gRPC", &c.GRPC.Addr),
+
+		stringSetting("DATABASE_URL", "db-url", "PostgreSQL connection URL", &c.DB.URL),
+		intSetting("DB_MAX_OPEN_CONNS", "db-max-open-conns", "max open DB connections", &c.DB.MaxOpenConns),
+		intSetting("DB_MAX_IDLE_CONNS", "db-max-idle-conns", "max idle DB connections", &c.DB.MaxIdleConns),
+		durationSetting("DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "max DB connection lifetime", &c.DB.ConnMaxLifetime),
+		intSetting("DB_CONNECT_ATTEMPTS", "db-connect-attempts", "DB ping attempts at startup", &c.DB.ConnectAttempts),
+		durationSetting("DB_CONNECT_RETRY_INTERVAL", "db-connect-retry-interval", "pause between DB ping attempts", &c.DB.ConnectRetryInterval),
+
+		stringSetting("LOG_LEVEL", "log-level", "log level: debug, info, warn, error", &c.Log.Level),
+
+		durationSetting("METRICS_REFRESH_INTERVAL", "metrics-refresh-interval", "open PR/review gauges refresh interval", &c.Metrics.RefreshInterval),
+
+		stringSetting("REVIEWER_ASSIGNMENT_STRATEGY", "reviewer-strategy", "reviewer assignment strategy: random or pr_hash", &c.Reviewers.Strategy),
+		intSetting("REVIEWER_COUNT", "reviewer-count", "reviewers assigned to a new pull request", &c.Reviewers.Count),
+
+		boolSetting("AUTH_ENABLED", "auth-enabled", "require API keys on API endpoints", &c.Auth.Enabled),
+		stringSetting("AUTH_BOOTSTRAP_ADMIN_KEY", "auth-bootstrap-admin-key", "admin API key accepted without a DB record", &c.Auth.BootstrapAdminKey),
+		stringSetting("AUTH_JWT_JWKS_FILE", "auth-jwt-jwks-file", "JWKS file for verifying JWT bearer tokens", &c.Auth.JWT.JWKSFile),
+		stringSetting("AUTH_JWT_JWKS_URL", "auth-jwt-jwks-url", "JWKS URL for verifying JWT bearer tokens", &c.Auth.JWT.JWKSURL),
+		durationSetting("AUTH_JWT_REFRESH_INTERVAL", "auth-jwt-refresh-interval", "JWKS cache refresh interval", &c.Auth.JWT.RefreshInterval),
+		stringSetting("AUTH_JWT_ISSUER", "auth-jwt-issuer", "required JWT iss claim", &c.Auth.JWT.Issuer),
+		stringSetting("AUTH_JWT_AUDIENCE", "auth-jwt-audience", "required JWT aud claim", &c.Auth.JWT.Audience),
+
+		boolSetting("RATE_LIMIT_ENABLED", "rate-limit-enabled", "enable per-client rate limiting", &c.RateLimit.Enabled),
+		floatSetting("RATE_LIMIT_IP_RPS", "rate-limit-ip-rps", "per-IP refill rate before authentication, req/s", &c.RateLimit.IP.RPS),
+		intSetting("RATE_LIMIT_IP_BURST", "rate-limit-ip-burst", "per-IP burst before authentication", &c.RateLimit.IP.Burst),
+		floatSetting("RATE_LIMIT_DEFAULT_RPS", "rate-limit-default-rps", "default route group refill rate, req/s", &c.RateLimit.Default.RPS),
+		intSetting("RATE_LIMIT_DEFAULT_BURST", "rate-limit-default-burst", "default route group burst", &c.RateLimit.Default.Burst),
+		floatSetting("RATE_LIMIT_PULL_REQUESTS_RPS", "rate-limit-pull-requests-rps", "/pullRequest/* refill rate, req/s", &c.RateLimit.PullRequests.RPS),
+		intSetting("RATE_LIMIT_PULL_REQUESTS_BURST", "rate-limit-pull-requests-burst", "/pullRequest/* burst", &c.RateLimit.PullRequests.Burst),
+		floatSetting("RATE_LIMIT_TEAMS_RPS", "rate-limit-teams-rps", "/team/* refill rate, req/s", &c.RateLimit.Teams.RPS),
+		intSetting("RATE_LIMIT_TEAMS_BURST", "rate-limit-teams-burst", "/team/* burst", &c.RateLimit.Teams.Burst),
+		floatSetting("RATE_LIMIT_STATS_RPS", "rate-limit-stats-rps", "/stats* refill rate, req/s", &c.RateLimit.Stats.RPS),
+		intSetting("RATE_LIMIT_STATS_BURST", "rate-limit-stats-burst", "/stats* burst", &c.RateLimit.Stats.Burst),
+
+		durationSetting("EVENTS_HEARTBEAT", "events-heartbeat", "event stream keep-alive comment interval", &c.Events.Heartbeat),
+		intSetting("EVENTS_BUFFER_SIZE", "events-buffer-size", "undelivered events per subscriber before its stream is closed", &c.Events.BufferSize),
+		durationSetting("EVENTS_RETENTION", "events-retention", "how long the event log is kept for Last-Event-ID resume", &c.Events.Retention),
+
+		intSetting("WEBSOCKET_MAX_CONNECTIONS", "websocket-max-connections", "inbox WebSocket connections per process", &c.WebSocket.MaxConnections),
+		intSetting("WEBSOCKET_BUFFER_SIZE", "websocket-buffer-size", "unsent events per WebSocket before it is closed as slow", &c.WebSocket.BufferSize),
+		durationSetting("WEBSOCKET_PING_INTERVAL", "websocket-ping-interval", "WebSocket server ping interval", &c.WebSocket.PingInterval),
+		durationSetting("WEBSOCKET_WRITE_TIMEOUT", "websocket-write-timeout", "WebSocket message write and pong wait timeout", &c.WebSocket.WriteTimeout),
+	}
+}
+
+func stringSetting(env, flag, usage string, dst *string) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(v string) error {
+		*dst = v
+		return nil
+	}}
+}
+
+func intSetting(env, flag, usage string, dst *int) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	}}
+}
+
+func floatSetting(env, flag, usage string, dst *float64) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*dst = f
+		return nil
+	}}
+}
+
+func boolSetting(env, flag, usage string, dst *bool) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*dst = b
+		return nil
+	}}
+}
+
+func durationSetting(env, flag, usage string, dst *Duration) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*dst = Duration(d)
+		return nil
+	}}
+}
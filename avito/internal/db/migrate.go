@@ -4,16 +4,125 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"io/fs"
+	"sort"
+	"strings"
 )
 
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// ApplyMigrations применяет ещё не применённые миграции из migrations/*.sql
+// в лексикографическом порядке имён. Каждая миграция выполняется в своей
+// транзакции, её имя фиксируется в schema_migrations.
 func ApplyMigrations(ctx context.Context, db *sql.DB) error {
-	sqlBytes, err := migrationsFS.ReadFile("migrations/001_init.sql")
+	_, err := db.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+             version    TEXT PRIMARY KEY,
+             applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+         )`)
 	if err != nil {
 		return err
 	}
-	_, err = db.ExecContext(ctx, string(sqlBytes))
-	return err
+
+	versions, err := migrationVersions()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		if err := applyMigration(ctx, db, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`,
+		version,
+	).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	sqlBytes, err := migrationsFS.ReadFile("migrations/" + version + ".sql")
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, string(sqlBytes)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version) VALUES ($1)`,
+		version,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PendingMigrations возвращает встроенные миграции, которые ещё не записаны
+// в schema_migrations. Пустой результат означает, что схема актуальна.
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]string, error) {
+	versions, err := migrationVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]struct{})
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := make([]string, 0)
+	for _, v := range versions {
+		if _, ok := applied[v]; !ok {
+			pending = append(pending, v)
+		}
+	}
+	return pending, nil
+}
+
+// migrationVersions возвращает имена встроенных миграций без расширения.
+func migrationVersions() ([]string, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		versions = append(versions, strings.TrimSuffix(e.Name(), ".sql"))
+	}
+	sort.Strings(versions)
+	return versions, nil
 }
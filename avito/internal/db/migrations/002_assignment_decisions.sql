@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS assignment_decisions (
+    id               BIGSERIAL PRIMARY KEY,
+    pull_request_id  TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    action           TEXT NOT NULL,
+    strategy         TEXT NOT NULL,
+    seed             BIGINT NOT NULL,
+    candidates       TEXT[] NOT NULL,
+    assigned         TEXT[] NOT NULL,
+    replaced_user_id TEXT,
+    decided_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS assignment_decisions_pr_idx
+    ON assignment_decisions (pull_request_id, id);
@@ -0,0 +1,3 @@
+-- пользователь, выведенный из команды, остаётся в системе (на него
+-- ссылаются PR и история назначений), но больше не состоит ни в одной команде
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
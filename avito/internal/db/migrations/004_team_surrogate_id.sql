@@ -0,0 +1,23 @@
+-- teams получают суррогатный ключ: пользователи ссылаются на id команды,
+-- поэтому team_name можно переименовывать, не трогая ссылки
+ALTER TABLE teams ADD COLUMN id BIGSERIAL;
+
+ALTER TABLE users ADD COLUMN team_id BIGINT;
+
+UPDATE users u
+SET team_id = t.id
+FROM teams t
+WHERE t.team_name = u.team_name;
+
+-- вместе с колонкой удаляется и внешний ключ users -> teams(team_name)
+ALTER TABLE users DROP COLUMN team_name;
+
+ALTER TABLE teams DROP CONSTRAINT teams_pkey;
+ALTER TABLE teams ADD PRIMARY KEY (id);
+ALTER TABLE teams ADD CONSTRAINT teams_team_name_key UNIQUE (team_name);
+
+ALTER TABLE users
+    ADD CONSTRAINT users_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS users_team_id_idx ON users (team_id);
@@ -0,0 +1,9 @@
+-- выборки ревью по пользователю и сортировка по датам
+CREATE INDEX IF NOT EXISTS pull_request_reviewers_user_idx
+    ON pull_request_reviewers (user_id, pull_request_id);
+
+CREATE INDEX IF NOT EXISTS pull_requests_created_at_idx
+    ON pull_requests (created_at, id);
+
+CREATE INDEX IF NOT EXISTS pull_requests_merged_at_idx
+    ON pull_requests (merged_at, id);
@@ -0,0 +1,31 @@
+-- история is_active нужна, чтобы учитывать число активных дней
+-- при оценке справедливости распределения ревью
+CREATE TABLE IF NOT EXISTS user_activity_changes (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_active  BOOLEAN NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_activity_changes_user_idx
+    ON user_activity_changes (user_id, changed_at);
+
+-- для уже существующих пользователей история неизвестна:
+-- считаем, что текущее состояние было всегда
+INSERT INTO user_activity_changes (user_id, is_active, changed_at)
+SELECT user_id, is_active, '-infinity'
+FROM users;
+
+CREATE OR REPLACE FUNCTION log_user_activity_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activity_changes (user_id, is_active, changed_at)
+        VALUES (NEW.user_id, NEW.is_active, now());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_activity_change
+    AFTER INSERT OR UPDATE OF is_active ON users
+    FOR EACH ROW EXECUTE FUNCTION log_user_activity_change();
@@ -0,0 +1,17 @@
+-- API-ключи: хранится только SHA-256 секрета и его префикс для опознания.
+-- Ключ team-lead привязан к команде (по id, чтобы пережить переименование),
+-- ключ member — к пользователю.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    name         TEXT NOT NULL,
+    key_hash     BYTEA NOT NULL UNIQUE,
+    prefix       TEXT NOT NULL,
+    role         TEXT NOT NULL CHECK (role IN ('admin', 'team-lead', 'member', 'bot')),
+    user_id      TEXT REFERENCES users(user_id) ON DELETE CASCADE,
+    team_id      BIGINT REFERENCES teams(id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    CHECK (role <> 'team-lead' OR team_id IS NOT NULL),
+    CHECK (role <> 'member' OR user_id IS NOT NULL)
+);
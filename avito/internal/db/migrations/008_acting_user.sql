@@ -0,0 +1,4 @@
+-- Кто выполнил действие: user_id из JWT или субъект API-ключа (key:<id>).
+-- NULL — действие выполнено без аутентификации или до появления колонки.
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS merged_by TEXT;
+ALTER TABLE assignment_decisions ADD COLUMN IF NOT EXISTS acted_by TEXT;
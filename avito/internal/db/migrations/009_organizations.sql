@@ -0,0 +1,111 @@
+-- организации (тенанты): все данные принадлежат ровно одной организации.
+-- Существующие данные переносятся в организацию default.
+CREATE TABLE IF NOT EXISTS organizations (
+    id         TEXT PRIMARY KEY CHECK (id ~ '^[a-z0-9][a-z0-9-]{0,62}$'),
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO organizations (id, name) VALUES ('default', 'Default organization')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE teams                  ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+ALTER TABLE users                  ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+ALTER TABLE pull_requests          ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+ALTER TABLE pull_request_reviewers ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+ALTER TABLE assignment_decisions   ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+ALTER TABLE user_activity_changes  ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+ALTER TABLE api_keys               ADD COLUMN org_id TEXT NOT NULL DEFAULT 'default' REFERENCES organizations(id);
+
+-- без значения по умолчанию забытый org_id в INSERT станет ошибкой,
+-- а не записью в чужую организацию
+ALTER TABLE teams                  ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE users                  ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pull_requests          ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pull_request_reviewers ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE assignment_decisions   ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE user_activity_changes  ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE api_keys               ALTER COLUMN org_id DROP DEFAULT;
+
+-- внешние ключи пересоздаются составными, с org_id: так база сама
+-- не даёт сослаться на пользователя, команду или PR другой организации
+ALTER TABLE users                  DROP CONSTRAINT users_team_id_fkey;
+ALTER TABLE pull_requests          DROP CONSTRAINT pull_requests_author_id_fkey;
+ALTER TABLE pull_request_reviewers DROP CONSTRAINT pull_request_reviewers_pull_request_id_fkey;
+ALTER TABLE pull_request_reviewers DROP CONSTRAINT pull_request_reviewers_user_id_fkey;
+ALTER TABLE assignment_decisions   DROP CONSTRAINT assignment_decisions_pull_request_id_fkey;
+ALTER TABLE user_activity_changes  DROP CONSTRAINT user_activity_changes_user_id_fkey;
+ALTER TABLE api_keys               DROP CONSTRAINT api_keys_user_id_fkey;
+ALTER TABLE api_keys               DROP CONSTRAINT api_keys_team_id_fkey;
+
+-- имена команд, user_id и ID PR уникальны в пределах организации
+ALTER TABLE teams DROP CONSTRAINT teams_team_name_key;
+ALTER TABLE teams ADD CONSTRAINT teams_org_id_team_name_key UNIQUE (org_id, team_name);
+ALTER TABLE teams ADD CONSTRAINT teams_org_id_id_key UNIQUE (org_id, id);
+
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD PRIMARY KEY (org_id, user_id);
+
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_pkey;
+ALTER TABLE pull_requests ADD PRIMARY KEY (org_id, id);
+
+ALTER TABLE pull_request_reviewers DROP CONSTRAINT pull_request_reviewers_pkey;
+ALTER TABLE pull_request_reviewers ADD PRIMARY KEY (org_id, pull_request_id, user_id);
+
+ALTER TABLE users
+    ADD CONSTRAINT users_team_fkey
+    FOREIGN KEY (org_id, team_id) REFERENCES teams(org_id, id) ON DELETE RESTRICT;
+ALTER TABLE pull_requests
+    ADD CONSTRAINT pull_requests_author_fkey
+    FOREIGN KEY (org_id, author_id) REFERENCES users(org_id, user_id) ON DELETE RESTRICT;
+ALTER TABLE pull_request_reviewers
+    ADD CONSTRAINT pull_request_reviewers_pull_request_fkey
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, id) ON DELETE CASCADE;
+ALTER TABLE pull_request_reviewers
+    ADD CONSTRAINT pull_request_reviewers_user_fkey
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE RESTRICT;
+ALTER TABLE assignment_decisions
+    ADD CONSTRAINT assignment_decisions_pull_request_fkey
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, id) ON DELETE CASCADE;
+ALTER TABLE user_activity_changes
+    ADD CONSTRAINT user_activity_changes_user_fkey
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+ALTER TABLE api_keys
+    ADD CONSTRAINT api_keys_user_fkey
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+ALTER TABLE api_keys
+    ADD CONSTRAINT api_keys_team_fkey
+    FOREIGN KEY (org_id, team_id) REFERENCES teams(org_id, id) ON DELETE CASCADE;
+
+-- индексы начинаются с org_id: каждый запрос фильтрует по организации
+DROP INDEX IF EXISTS pull_request_reviewers_user_idx;
+CREATE INDEX IF NOT EXISTS pull_request_reviewers_user_idx
+    ON pull_request_reviewers (org_id, user_id, pull_request_id);
+
+DROP INDEX IF EXISTS pull_requests_created_at_idx;
+CREATE INDEX IF NOT EXISTS pull_requests_created_at_idx
+    ON pull_requests (org_id, created_at, id);
+
+DROP INDEX IF EXISTS pull_requests_merged_at_idx;
+CREATE INDEX IF NOT EXISTS pull_requests_merged_at_idx
+    ON pull_requests (org_id, merged_at, id);
+
+DROP INDEX IF EXISTS assignment_decisions_pr_idx;
+CREATE INDEX IF NOT EXISTS assignment_decisions_pr_idx
+    ON assignment_decisions (org_id, pull_request_id, id);
+
+DROP INDEX IF EXISTS user_activity_changes_user_idx;
+CREATE INDEX IF NOT EXISTS user_activity_changes_user_idx
+    ON user_activity_changes (org_id, user_id, changed_at);
+
+CREATE INDEX IF NOT EXISTS api_keys_org_idx ON api_keys (org_id, id);
+
+CREATE OR REPLACE FUNCTION log_user_activity_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activity_changes (org_id, user_id, is_active, changed_at)
+        VALUES (NEW.org_id, NEW.user_id, NEW.is_active, now());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
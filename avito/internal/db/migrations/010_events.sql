@@ -0,0 +1,33 @@
+-- журнал событий PR для /events/stream: по нему клиенты догоняют
+-- пропущенное после переподключения (Last-Event-ID)
+CREATE TABLE IF NOT EXISTS pr_events (
+    id              BIGSERIAL PRIMARY KEY,
+    org_id          TEXT NOT NULL REFERENCES organizations(id),
+    type            TEXT NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    -- команда автора PR на момент события
+    team_name       TEXT,
+    -- автор, ревьюверы и снятый ревьювер: по ним фильтрует user_id
+    user_ids        TEXT[] NOT NULL,
+    pull_request    JSONB NOT NULL,
+    old_reviewer_id TEXT,
+    new_reviewer_id TEXT,
+    acted_by        TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS pr_events_org_id_id_idx ON pr_events (org_id, id);
+CREATE INDEX IF NOT EXISTS pr_events_created_at_idx ON pr_events (created_at);
+
+-- каждая реплика слушает pr_events и раздаёт событие своим подписчикам;
+-- уведомление уходит при фиксации транзакции, в которой событие записано
+CREATE OR REPLACE FUNCTION notify_pr_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('pr_events', NEW.org_id || ':' || NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_events_notify ON pr_events;
+CREATE TRIGGER pr_events_notify AFTER INSERT ON pr_events
+    FOR EACH ROW EXECUTE FUNCTION notify_pr_event();
@@ -0,0 +1,26 @@
+-- Ключи team-lead больше не удаляются каскадом вместе с командой:
+-- DeleteTeam отзывает их явно и отвязывает от команды, а отозванный ключ
+-- остаётся в списке ключей организации.
+ALTER TABLE api_keys DROP CONSTRAINT api_keys_team_fkey;
+ALTER TABLE api_keys
+    ADD CONSTRAINT api_keys_team_fkey
+    FOREIGN KEY (org_id, team_id) REFERENCES teams(org_id, id) ON DELETE RESTRICT;
+
+-- проверка из 007 объявлена без имени: ищем её по определению
+DO $$
+DECLARE
+    c TEXT;
+BEGIN
+    SELECT conname INTO c
+    FROM pg_constraint
+    WHERE conrelid = 'api_keys'::regclass
+      AND contype = 'c'
+      AND pg_get_constraintdef(oid) LIKE '%team-lead%';
+    IF c IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE api_keys DROP CONSTRAINT %I', c);
+    END IF;
+END $$;
+
+ALTER TABLE api_keys
+    ADD CONSTRAINT api_keys_team_lead_check
+    CHECK (role <> 'team-lead' OR team_id IS NOT NULL OR revoked_at IS NOT NULL);
@@ -0,0 +1,10 @@
+-- ListPRs сортирует по COALESCE(created_at/merged_at, ±infinity): индексы
+-- по самим колонкам под это выражение не подходят, поэтому индексируем
+-- его же. Выражения должны совпадать с prs_repo.go.
+DROP INDEX IF EXISTS pull_requests_created_at_idx;
+CREATE INDEX IF NOT EXISTS pull_requests_created_at_idx
+    ON pull_requests (org_id, COALESCE(created_at, '-infinity'::timestamptz), id);
+
+DROP INDEX IF EXISTS pull_requests_merged_at_idx;
+CREATE INDEX IF NOT EXISTS pull_requests_merged_at_idx
+    ON pull_requests (org_id, COALESCE(merged_at, 'infinity'::timestamptz), id);
@@ -1,45 +1,43 @@
 package db
 
 import (
+	"avito/internal/config"
 	"context"
 	"database/sql"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func MustConnect(ctx context.Context) *sql.DB {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL is not set")
-	}
-
-	db, err := sql.Open("pgx", dsn)
+func MustConnect(ctx context.Context, cfg config.DB) *sql.DB {
+	db, err := sql.Open("pgx", cfg.URL)
 	if err != nil {
-		log.Fatalf("failed to open db: %v", err)
+		slog.Error("failed to open db", "error", err)
+		os.Exit(1)
 	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Hour)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime))
 
-	const maxAttempts = 30
+	maxAttempts := cfg.ConnectAttempts
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		pingCtx, cancel := context.WithTimeout(ctx, 1*time.Second)
 		err = db.PingContext(pingCtx)
 		cancel()
 
 		if err == nil {
-			log.Println("database connection established")
+			slog.Info("database connection established")
 			return db
 		}
 
-		log.Printf("db not ready yet (attempt %d/%d): %v", attempt, maxAttempts, err)
-		time.Sleep(1 * time.Second)
+		slog.Warn("db not ready yet", "attempt", attempt, "max_attempts", maxAttempts, "error", err)
+		time.Sleep(time.Duration(cfg.ConnectRetryInterval))
 	}
 
-	log.Fatalf("failed to ping db after %d attempts: %v", maxAttempts, err)
+	slog.Error("failed to ping db", "attempts", maxAttempts, "error", err)
+	os.Exit(1)
 	return nil
 }
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// Role определяет набор разрешённых действий.
+type Role string
+
+const (
+	// RoleAdmin может всё, включая управление ключами.
+	RoleAdmin Role = "admin"
+	// RoleTeamLead управляет своей командой: составом и активностью участников.
+	RoleTeamLead Role = "team-lead"
+	// RoleMember читает данные, работает с PR и меняет только свой is_active.
+	RoleMember Role = "member"
+	// RoleBot — автоматизация (CI): чтение и операции с PR.
+	RoleBot Role = "bot"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleTeamLead, RoleMember, RoleBot:
+		return true
+	}
+	return false
+}
+
+// APIKey — выпущенный API-ключ. Сам секрет не хранится, только его хэш.
+// TeamName задан у ключей team-lead, UserID — у ключей member.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	OrgID      string     `json:"org_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       Role       `json:"role"`
+	UserID     string     `json:"user_id,omitempty"`
+	TeamName   string     `json:"team_name,omitempty"`
+	TeamID     int64      `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Principal — аутентифицированный вызывающий.
+type Principal struct {
+	// Subject — идентификатор для журналов: key:<id> или bootstrap.
+	Subject string `json:"subject"`
+	Name    string `json:"name"`
+	Role    Role   `json:"role"`
+	// OrgID — организация вызывающего. Пуст у администратора платформы
+	// (bootstrap-ключ): он выбирает организацию заголовком X-Org-ID.
+	OrgID    string `json:"org_id,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+	// TeamID — команда team-lead (см. Team.ID). Права сверяются по нему,
+	// а не по имени, поэтому переименование команды их не меняет.
+	TeamID int64 `json:"-"`
+}
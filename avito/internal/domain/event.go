@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// EventType — вид изменения PR в журнале событий.
+type EventType string
+
+const (
+	EventPRCreated    EventType = "pr.created"
+	EventPRReassigned EventType = "pr.reassigned"
+	EventPRMerged     EventType = "pr.merged"
+)
+
+// Event — запись журнала событий PR. ID растёт монотонно и служит
+// идентификатором события в потоке (Last-Event-ID).
+type Event struct {
+	ID    int64     `json:"id"`
+	OrgID string    `json:"-"`
+	Type  EventType `json:"type"`
+	// PullRequest — состояние PR сразу после изменения.
+	PullRequest PullRequest `json:"pull_request"`
+	// TeamName — команда автора PR; пусто, если автор вне команды.
+	TeamName string `json:"team_name,omitempty"`
+	// OldReviewerID и NewReviewerID заданы у pr.reassigned.
+	OldReviewerID string    `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string    `json:"new_reviewer_id,omitempty"`
+	ActedBy       string    `json:"acted_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UserIDs — пользователи, которых касается событие: автор, ревьюверы
+// и снятый ревьювер.
+func (e Event) UserIDs() []string {
+	ids := make([]string, 0, len(e.PullRequest.AssignedReviewers)+2)
+	ids = append(ids, e.PullRequest.AuthorID)
+	ids = append(ids, e.PullRequest.AssignedReviewers...)
+	if e.OldReviewerID != "" {
+		ids = append(ids, e.OldReviewerID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Organization — тенант: команды, пользователи, PR и API-ключи
+// принадлежат ровно одной организации и не видны из других.
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
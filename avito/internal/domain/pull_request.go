@@ -17,6 +17,8 @@ type PullRequest struct {
 	AssignedReviewers []string          `json:"assigned_reviewers"`
 	CreatedAt         *time.Time        `json:"createdAt,omitempty"`
 	MergedAt          *time.Time        `json:"mergedAt,omitempty"`
+	// MergedBy — кто выполнил merge (см. auth.Actor).
+	MergedBy string `json:"mergedBy,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -25,3 +27,35 @@ type PullRequestShort struct {
 	AuthorID string            `json:"author_id"`
 	Status   PullRequestStatus `json:"status"`
 }
+
+// AssignmentStrategy определяет, откуда берётся seed для выбора ревьюверов.
+type AssignmentStrategy string
+
+const (
+	// AssignmentRandom — seed берётся из источника случайности сервиса.
+	AssignmentRandom AssignmentStrategy = "RANDOM"
+	// AssignmentPRHash — seed вычисляется из хэша ID PR, выбор детерминирован.
+	AssignmentPRHash AssignmentStrategy = "PR_HASH"
+)
+
+type AssignmentAction string
+
+const (
+	AssignmentActionCreate   AssignmentAction = "CREATE"
+	AssignmentActionReassign AssignmentAction = "REASSIGN"
+)
+
+// AssignmentDecision фиксирует одно решение о назначении ревьюверов.
+// По Candidates и Seed выбор можно воспроизвести через service.PickReviewers.
+type AssignmentDecision struct {
+	PRID           string             `json:"pull_request_id"`
+	Action         AssignmentAction   `json:"action"`
+	Strategy       AssignmentStrategy `json:"strategy"`
+	Seed           int64              `json:"seed"`
+	Candidates     []string           `json:"candidates"`
+	Assigned       []string           `json:"assigned"`
+	ReplacedUserID string             `json:"replaced_user_id,omitempty"`
+	// ActedBy — кто инициировал решение; пусто без аутентификации.
+	ActedBy   string    `json:"acted_by,omitempty"`
+	DecidedAt time.Time `json:"decided_at"`
+}
@@ -7,6 +7,9 @@ type TeamMember struct {
 }
 
 type Team struct {
+	// ID — суррогатный ключ команды: не меняется при переименовании,
+	// по нему сверяются права team-lead. Наружу не отдаётся.
+	ID       int64        `json:"-"`
 	TeamName string       `json:"team_name"`
 	Members  []TeamMember `json:"members"`
 }
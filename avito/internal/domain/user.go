@@ -5,4 +5,6 @@ type User struct {
 	Username string `json:"username"`
 	TeamName string `json:"team_name"`
 	IsActive bool   `json:"is_active"`
+	// TeamID — ID команды (см. Team.ID); заполняется GetUser.
+	TeamID int64 `json:"-"`
 }
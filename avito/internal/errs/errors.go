@@ -9,11 +9,44 @@ const (
 	CodeNotAssigned ErrorCode = "NOT_ASSIGNED"
 	CodeNoCandidate ErrorCode = "NO_CANDIDATE"
 	CodeNotFound    ErrorCode = "NOT_FOUND"
+
+	CodeUserInOtherTeam ErrorCode = "USER_IN_OTHER_TEAM"
+	CodeTeamNotEmpty    ErrorCode = "TEAM_NOT_EMPTY"
+	CodeOrgExists       ErrorCode = "ORG_EXISTS"
+	CodeInvalidCursor   ErrorCode = "INVALID_CURSOR"
+
+	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	CodeForbidden    ErrorCode = "FORBIDDEN"
+
+	CodeRateLimited     ErrorCode = "RATE_LIMITED"
+	CodePayloadTooLarge ErrorCode = "PAYLOAD_TOO_LARGE"
+	// CodeUnavailable — инстанс временно не может принять запрос,
+	// например исчерпан лимит соединений; клиенту стоит повторить позже.
+	CodeUnavailable ErrorCode = "UNAVAILABLE"
+
+	// CodeMethodNotAllowed — маршрут есть, но не для этого HTTP-метода.
+	CodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	// CodeBadRequest — тело запроса не разбирается как JSON.
+	CodeBadRequest ErrorCode = "BAD_REQUEST"
+	// CodeValidationFailed — запрос разобран, но значения полей недопустимы;
+	// подробности в AppError.Fields.
+	CodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	// CodeInternal — непредвиденная ошибка; подробности клиенту не отдаются.
+	CodeInternal ErrorCode = "INTERNAL"
 )
 
+// FieldError — ошибка значения одного поля запроса.
+type FieldError struct {
+	// Field — имя поля как в запросе; для элементов массивов с индексом,
+	// например members[1].user_id.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 type AppError struct {
-	Code ErrorCode
-	Msg  string
+	Code   ErrorCode
+	Msg    string
+	Fields []FieldError
 }
 
 func (e *AppError) Error() string {
@@ -23,3 +56,12 @@ func (e *AppError) Error() string {
 func New(code ErrorCode, msg string) *AppError {
 	return &AppError{Code: code, Msg: msg}
 }
+
+// Invalid — ошибка VALIDATION_FAILED для одного поля.
+func Invalid(field, msg string) *AppError {
+	return &AppError{
+		Code:   CodeValidationFailed,
+		Msg:    field + ": " + msg,
+		Fields: []FieldError{{Field: field, Message: msg}},
+	}
+}
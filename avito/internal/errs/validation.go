@@ -0,0 +1,123 @@
+package errs
+
+import (
+	"avito/internal/domain"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxIDLength — предельная длина идентификаторов и имён.
+const MaxIDLength = 128
+
+// idPattern — допустимые символы user_id и pull_request_id.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+// Validator собирает ошибки полей запроса, чтобы вернуть их все разом,
+// а не по одной на запрос.
+type Validator struct {
+	fields []FieldError
+}
+
+// Add записывает ошибку поля field.
+func (v *Validator) Add(field, format string, args ...any) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Required проверяет, что строковое поле задано.
+func (v *Validator) Required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// ID проверяет новый идентификатор: задан, не длиннее MaxIDLength, из
+// латинских букв, цифр и символов . _ : -, начинается с буквы или цифры.
+func (v *Validator) ID(field, value string) bool {
+	if !v.Required(field, value) {
+		return false
+	}
+	if len(value) > MaxIDLength {
+		v.Add(field, "must be at most %d characters", MaxIDLength)
+		return false
+	}
+	if !idPattern.MatchString(value) {
+		v.Add(field, "must contain only latin letters, digits, '.', '_', ':' or '-' and start with a letter or digit")
+		return false
+	}
+	return true
+}
+
+// Name проверяет имя (команды, PR, пользователя): задано, не длиннее
+// MaxIDLength символов, без управляющих символов и пробелов по краям.
+func (v *Validator) Name(field, value string) bool {
+	if !v.Required(field, value) {
+		return false
+	}
+	if utf8.RuneCountInString(value) > MaxIDLength {
+		v.Add(field, "must be at most %d characters", MaxIDLength)
+		return false
+	}
+	if strings.TrimSpace(value) != value {
+		v.Add(field, "must not start or end with whitespace")
+		return false
+	}
+	if strings.ContainsFunc(value, unicode.IsControl) {
+		v.Add(field, "must not contain control characters")
+		return false
+	}
+	return true
+}
+
+// Err возвращает VALIDATION_FAILED со всеми собранными ошибками или nil.
+func (v *Validator) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	msg := make([]string, 0, len(v.fields))
+	for _, f := range v.fields {
+		msg = append(msg, f.Field+": "+f.Message)
+	}
+	return &AppError{
+		Code:   CodeValidationFailed,
+		Msg:    strings.Join(msg, "; "),
+		Fields: v.fields,
+	}
+}
+
+// Members проверяет участников команды: user_id — новый идентификатор,
+// username задан, user_id не повторяются.
+func (v *Validator) Members(field string, members []domain.TeamMember) {
+	seen := make(map[string]int, len(members))
+	for i, m := range members {
+		prefix := fmt.Sprintf("%s[%d]", field, i)
+		if v.ID(prefix+".user_id", m.UserID) {
+			if j, ok := seen[m.UserID]; ok {
+				v.Add(prefix+".user_id", "duplicates %s[%d].user_id", field, j)
+			} else {
+				seen[m.UserID] = i
+			}
+		}
+		v.Name(prefix+".username", m.Username)
+	}
+}
+
+// UserIDs проверяет список user_id: без пустых и повторов.
+func (v *Validator) UserIDs(field string, ids []string) {
+	seen := make(map[string]int, len(ids))
+	for i, id := range ids {
+		name := fmt.Sprintf("%s[%d]", field, i)
+		if !v.Required(name, id) {
+			continue
+		}
+		if j, ok := seen[id]; ok {
+			v.Add(name, "duplicates %s[%d]", field, j)
+		} else {
+			seen[id] = i
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package events раздаёт события PR подписчикам: потоку /events/stream
+// и WebSocket-инбоксам. События пишутся в журнал pr_events; Postgres
+// уведомляет о каждой записи через LISTEN/NOTIFY все реплики, и Listener
+// каждой из них публикует событие в свой Broker.
+package events
+
+import (
+	"avito/internal/domain"
+	"avito/internal/metrics"
+	"avito/internal/repository"
+	"errors"
+	"sync"
+)
+
+// DefaultBufferSize — сколько событий подписчик может не забрать,
+// прежде чем брокер его отключит.
+const DefaultBufferSize = 256
+
+// Причины, по которым брокер снимает подписку; см. Subscription.Err.
+var (
+	// ErrOverflow — подписчик не успевал забирать события.
+	ErrOverflow = errors.New("subscriber buffer overflow")
+	// ErrReset — доставка прерывалась, события могли потеряться.
+	ErrReset = errors.New("event delivery interrupted")
+	// ErrClosed — брокер остановлен.
+	ErrClosed = errors.New("broker closed")
+)
+
+// Broker раздаёт события подписчикам процесса. Медленного подписчика он
+// не ждёт: при переполнении буфера подписка закрывается, и клиент
+// догоняет пропущенное по журналу, переподключившись с Last-Event-ID.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+}
+
+func NewBroker(bufferSize int) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Broker{subs: make(map[*Subscription]struct{}), buffer: bufferSize}
+}
+
+// Subscription — подписка на события одной организации по фильтру.
+type Subscription struct {
+	broker *Broker
+	orgID  string
+	filter repository.EventFilter
+	ch     chan domain.Event
+	err    error
+}
+
+// Events отдаёт события подписки. Канал закрывается, когда подписка
+// снята: вызовом Close, переполнением буфера или остановкой брокера.
+func (s *Subscription) Events() <-chan domain.Event {
+	return s.ch
+}
+
+// Err сообщает, почему брокер снял подписку: ErrOverflow, ErrReset или
+// ErrClosed. Имеет смысл после закрытия канала Events; nil, если
+// подписку закрыл сам подписчик.
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+// Close снимает подписку; повторный вызов ничего не делает.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s, nil)
+}
+
+// Subscribe подписывает на события организации orgID, подходящие под f.
+// buffer — сколько событий подписчик может не забрать; 0 — размер
+// по умолчанию брокера. После Close брокера возвращает уже закрытую подписку.
+func (b *Broker) Subscribe(orgID string, f repository.EventFilter, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = b.buffer
+	}
+	s := &Subscription{
+		broker: b,
+		orgID:  orgID,
+		filter: f,
+		ch:     make(chan domain.Event, buffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		s.err = ErrClosed
+		close(s.ch)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	metrics.EventSubscribers.Inc()
+	return s
+}
+
+// Publish передаёт событие подходящим подписчикам без блокировки.
+func (b *Broker) Publish(e domain.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs {
+		if s.orgID != e.OrgID || !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			b.remove(s, ErrOverflow)
+			metrics.EventSubscribersDropped.Inc()
+		}
+	}
+}
+
+// Reset закрывает все подписки. Нужен, когда события могли быть потеряны,
+// например при разрыве LISTEN: клиенты переподключатся и догонят журнал.
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs {
+		b.remove(s, ErrReset)
+	}
+}
+
+// Close закрывает все подписки и отклоняет новые; вызывается при
+// остановке, чтобы открытые потоки не держали сервер.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		b.remove(s, ErrClosed)
+	}
+}
+
+// remove вызывается под b.mu.
+func (b *Broker) remove(s *Subscription, reason error) {
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	s.err = reason
+	close(s.ch)
+	metrics.EventSubscribers.Dec()
+}
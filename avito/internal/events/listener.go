@@ -0,0 +1,157 @@
+package events
+
+import (
+	"avito/internal/repository"
+	"avito/internal/tenant"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Channel — канал NOTIFY, в который триггер pr_events_notify пишет
+// "<org_id>:<id>" каждого записанного события.
+const Channel = "pr_events"
+
+const (
+	// pingInterval — как часто соединение LISTEN проверяется, если
+	// уведомлений нет; с той же частотой Listener сообщает о себе в beat.
+	pingInterval = 15 * time.Second
+	// maxRetryDelay ограничивает паузу между попытками переподключения.
+	maxRetryDelay = 30 * time.Second
+)
+
+// Listener слушает Channel на отдельном соединении и публикует новые
+// события в Broker.
+type Listener struct {
+	db     *sql.DB
+	events repository.EventRepository
+	broker *Broker
+}
+
+func NewListener(db *sql.DB, events repository.EventRepository, broker *Broker) *Listener {
+	return &Listener{db: db, events: events, broker: broker}
+}
+
+// PingInterval — наибольший промежуток между вызовами beat у работающего
+// Listener.
+func PingInterval() time.Duration {
+	return pingInterval
+}
+
+// Run слушает уведомления, пока ctx не отменён, и переподключается при
+// ошибках. Блокирует вызывающего. Если beat не nil, он получает результат
+// каждой проверки соединения.
+func (l *Listener) Run(ctx context.Context, beat func(error)) {
+	delay := time.Second
+	for {
+		err := l.listen(ctx, beat)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// пока соединения не было, уведомления могли потеряться
+		l.broker.Reset()
+		slog.ErrorContext(ctx, "event listener failed", "error", err, "retry_in", delay.String())
+		if beat != nil {
+			beat(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// listen держит одно соединение с LISTEN до первой ошибки. Соединение
+// затем выбрасывается из пула, чтобы LISTEN не достался другим запросам.
+func (l *Listener) listen(ctx context.Context, beat func(error)) error {
+	c, err := l.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var listenErr error
+	err = c.Raw(func(dc any) error {
+		pc := dc.(*stdlib.Conn).Conn()
+		listenErr = l.wait(ctx, pc, beat)
+		return driver.ErrBadConn
+	})
+	if listenErr != nil {
+		return listenErr
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return nil
+	}
+	return err
+}
+
+func (l *Listener) wait(ctx context.Context, pc *pgx.Conn, beat func(error)) error {
+	if _, err := pc.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "event listener started", "channel", Channel)
+
+	for {
+		if beat != nil {
+			beat(nil)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, pingInterval)
+		n, err := pc.WaitForNotification(waitCtx)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded):
+			// уведомлений не было: убеждаемся, что соединение живо
+			if err := pc.Ping(ctx); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+
+		if err := l.publish(ctx, n.Payload); err != nil {
+			// подписчики не получат событие: пусть догонят его по журналу
+			l.broker.Reset()
+			slog.ErrorContext(ctx, "publish event", "payload", n.Payload, "error", err)
+		}
+	}
+}
+
+// publish загружает событие из уведомления и передаёт его брокеру.
+func (l *Listener) publish(ctx context.Context, payload string) error {
+	org, rawID, ok := strings.Cut(payload, ":")
+	if !ok {
+		return fmt.Errorf("malformed notification %q", payload)
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed notification %q: %w", payload, err)
+	}
+
+	e, err := l.events.GetEvent(tenant.WithOrg(ctx, org), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		// удалено очисткой журнала раньше, чем дошло уведомление
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	l.broker.Publish(*e)
+	return nil
+}
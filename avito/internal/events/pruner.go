@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Pruner удаляет из журнала события старше срока хранения.
+type Pruner interface {
+	PruneEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RunPruner раз в interval удаляет события старше retention, пока ctx не
+// отменён. Блокирует вызывающего. Клиент, отставший больше чем на
+// retention, после переподключения получит только сохранившиеся события.
+func RunPruner(ctx context.Context, p Pruner, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.PruneEvents(ctx, time.Now().Add(-retention))
+		switch {
+		case err != nil && ctx.Err() == nil:
+			slog.ErrorContext(ctx, "prune events", "error", err)
+		case n > 0:
+			slog.InfoContext(ctx, "events pruned", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package graphql
+
+import (
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"context"
+	"errors"
+)
+
+// resolverError — ошибка резолвера в ответе GraphQL. Код ошибки сервиса
+// и ошибки полей попадают в extensions, как в теле ошибки HTTP API.
+type resolverError struct {
+	err *errs.AppError
+}
+
+func (e resolverError) Error() string {
+	return e.err.Msg
+}
+
+func (e resolverError) Extensions() map[string]any {
+	ext := map[string]any{"code": string(e.err.Code)}
+	if len(e.err.Fields) > 0 {
+		ext["details"] = e.err.Fields
+	}
+	return ext
+}
+
+// toError переводит ошибку сервиса в ошибку резолвера. Прочие ошибки
+// логируются и отдаются как INTERNAL без подробностей.
+func toError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *errs.AppError
+	if !errors.As(err, &appErr) {
+		logging.FromContext(ctx).ErrorContext(ctx, "graphql resolver failed", "error", err.Error())
+		appErr = errs.New(errs.CodeInternal, "internal error")
+	}
+	logging.Annotate(ctx, "error_code", string(appErr.Code))
+	return resolverError{err: appErr}
+}
@@ -0,0 +1,26 @@
+package graphql
+
+import "context"
+
+// Limiter списывает вызов корневого поля из лимита группы маршрутов
+// (ratelimit.Group*) и возвращает ошибку при отказе. Поля расходуют те же
+// корзины, что и соответствующие маршруты HTTP, так что переход на
+// GraphQL не обходит лимиты групп.
+type Limiter func(ctx context.Context, group string) error
+
+type limiterKey struct{}
+
+// WithLimiter задаёт лимиты корневых полей запроса; без него поля не
+// ограничиваются.
+func WithLimiter(ctx context.Context, l Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+// limit списывает корневое поле из лимита группы group.
+func limit(ctx context.Context, group string) error {
+	l, ok := ctx.Value(limiterKey{}).(Limiter)
+	if !ok {
+		return nil
+	}
+	return toError(ctx, l(ctx, group))
+}
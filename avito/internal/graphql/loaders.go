@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"avito/internal/domain"
+	"avito/internal/service"
+	"context"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaderWait — сколько загрузчик копит ключи перед запросом. Резолверы
+// соседних полей выполняются параллельно и укладываются в это окно.
+const loaderWait = 2 * time.Millisecond
+
+// loaders собирают обращения резолверов за связанными объектами в пачки:
+// участники всех команд ответа, их открытые ревью, авторы и ревьюверы
+// PR загружаются одним запросом на уровень вложенности, а не на объект.
+// Создаются на каждый запрос: кэш не переживает запрос и не смешивает
+// данные организаций.
+type loaders struct {
+	teams       *dataloader.Loader[string, *domain.Team]
+	users       *dataloader.Loader[string, *domain.User]
+	prs         *dataloader.Loader[string, *domain.PullRequest]
+	openReviews *dataloader.Loader[string, []domain.PullRequest]
+}
+
+func newLoaders(svcs *service.Services) *loaders {
+	return &loaders{
+		teams: newLoader(svcs.Teams.GetTeams, func(t domain.Team) string { return t.TeamName }),
+		users: newLoader(svcs.Users.GetUsers, func(u domain.User) string { return u.ID }),
+		prs:   newLoader(svcs.PullRequests.GetPRs, func(pr domain.PullRequest) string { return pr.ID }),
+		openReviews: dataloader.NewBatchedLoader(
+			func(ctx context.Context, userIDs []string) []*dataloader.Result[[]domain.PullRequest] {
+				byUser, err := svcs.PullRequests.GetOpenReviews(ctx, userIDs)
+				res := make([]*dataloader.Result[[]domain.PullRequest], len(userIDs))
+				for i, id := range userIDs {
+					res[i] = &dataloader.Result[[]domain.PullRequest]{Data: byUser[id], Error: err}
+				}
+				return res
+			},
+			dataloader.WithWait[string, []domain.PullRequest](loaderWait),
+		),
+	}
+}
+
+// newLoader строит загрузчик поверх пакетного метода сервиса. Ключ без
+// объекта в ответе загружается как nil: для резолвера это «не найдено».
+func newLoader[V any](fetch func(context.Context, []string) ([]V, error), key func(V) string) *dataloader.Loader[string, *V] {
+	batch := func(ctx context.Context, keys []string) []*dataloader.Result[*V] {
+		items, err := fetch(ctx, keys)
+		byKey := make(map[string]*V, len(items))
+		for i := range items {
+			byKey[key(items[i])] = &items[i]
+		}
+		res := make([]*dataloader.Result[*V], len(keys))
+		for i, k := range keys {
+			res[i] = &dataloader.Result[*V]{Data: byKey[k], Error: err}
+		}
+		return res
+	}
+	return dataloader.NewBatchedLoader(batch, dataloader.WithWait[string, *V](loaderWait))
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// loadAll загружает объекты по ключам одной пачкой, пропуская ненайденные.
+func loadAll[V any](ctx context.Context, l *dataloader.Loader[string, *V], keys []string) ([]*V, error) {
+	items, errs := l.LoadMany(ctx, keys)()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	res := make([]*V, 0, len(items))
+	for _, v := range items {
+		if v != nil {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,339 @@
+package graphql
+
+import (
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/ratelimit"
+	"avito/internal/repository"
+	"avito/internal/service"
+	"context"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// resolver — корневой резолвер: поля Query и Mutation. Каждое поле
+// сначала списывается из лимита группы, как маршрут HTTP с теми же данными:
+// team — /team/get, pullRequest и мутации — /pullRequest/* и /v2,
+// stats — /stats, остальное — списки группы default.
+type resolver struct {
+	svcs *service.Services
+}
+
+// Перечисления схемы в значения репозитория. Незаданный аргумент даёт
+// то же значение по умолчанию, что и пустой параметр HTTP API.
+var (
+	sortOrders = map[string]repository.SortOrder{
+		"ASC":  repository.SortAsc,
+		"DESC": repository.SortDesc,
+	}
+	userSorts = map[string]repository.UserSortField{
+		"USER_ID":  repository.UserSortByID,
+		"USERNAME": repository.UserSortByUsername,
+	}
+	prSorts = map[string]repository.PRSortField{
+		"PULL_REQUEST_ID": repository.PRSortByID,
+		"CREATED_AT":      repository.PRSortByCreatedAt,
+		"MERGED_AT":       repository.PRSortByMergedAt,
+	}
+	buckets = map[string]repository.StatsBucket{
+		"DAY":   repository.StatsBucketDay,
+		"WEEK":  repository.StatsBucketWeek,
+		"MONTH": repository.StatsBucketMonth,
+	}
+	bucketToEnum = map[repository.StatsBucket]string{
+		repository.StatsBucketDay:   "DAY",
+		repository.StatsBucketWeek:  "WEEK",
+		repository.StatsBucketMonth: "MONTH",
+	}
+)
+
+// enum возвращает значение аргумента-перечисления или def, если он не задан.
+// Значения вне схемы отсекает валидация запроса до резолвера.
+func enum[V any](m map[string]V, arg *string, def V) V {
+	if arg == nil {
+		return def
+	}
+	return m[*arg]
+}
+
+func page(limit *int32, cursor *string) (repository.Page, error) {
+	var p repository.Page
+	if limit != nil {
+		if *limit <= 0 {
+			return p, errs.Invalid("limit", "must be a positive integer")
+		}
+		p.Limit = int(*limit)
+	}
+	if cursor != nil {
+		p.Cursor = *cursor
+	}
+	return p, nil
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
+
+func timeArg(t *graphql.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func (r *resolver) Team(ctx context.Context, args struct{ Name string }) (*teamResolver, error) {
+	if err := limit(ctx, ratelimit.GroupTeams); err != nil {
+		return nil, err
+	}
+
+	team, err := loadersFrom(ctx).teams.Load(ctx, args.Name)()
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	if team == nil {
+		return nil, nil
+	}
+	return &teamResolver{name: team.TeamName}, nil
+}
+
+func (r *resolver) Teams(ctx context.Context, args struct {
+	Order  *string
+	Limit  *int32
+	Cursor *string
+}) (*teamConnection, error) {
+	if err := limit(ctx, ratelimit.GroupDefault); err != nil {
+		return nil, err
+	}
+
+	p, err := page(args.Limit, args.Cursor)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	res, err := r.svcs.Teams.ListTeams(ctx, repository.TeamFilter{
+		Order: enum(sortOrders, args.Order, repository.SortAsc),
+		Page:  p,
+	})
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+
+	items := make([]*teamResolver, 0, len(res.Items))
+	for _, t := range res.Items {
+		items = append(items, &teamResolver{name: t.TeamName})
+	}
+	return &teamConnection{items: items, next: res.NextCursor}, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	if err := limit(ctx, ratelimit.GroupDefault); err != nil {
+		return nil, err
+	}
+
+	return loadUser(ctx, string(args.ID))
+}
+
+func (r *resolver) Users(ctx context.Context, args struct {
+	TeamName *string
+	IsActive *bool
+	Sort     *string
+	Order    *string
+	Limit    *int32
+	Cursor   *string
+}) (*userConnection, error) {
+	if err := limit(ctx, ratelimit.GroupDefault); err != nil {
+		return nil, err
+	}
+
+	p, err := page(args.Limit, args.Cursor)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	res, err := r.svcs.Users.ListUsers(ctx, repository.UserFilter{
+		TeamName: deref(args.TeamName),
+		IsActive: args.IsActive,
+		Sort:     enum(userSorts, args.Sort, repository.UserSortByID),
+		Order:    enum(sortOrders, args.Order, repository.SortAsc),
+		Page:     p,
+	})
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+
+	items := make([]*userResolver, 0, len(res.Items))
+	for _, u := range res.Items {
+		items = append(items, &userResolver{u: u})
+	}
+	return &userConnection{items: items, next: res.NextCursor}, nil
+}
+
+func (r *resolver) PullRequest(ctx context.Context, args struct{ ID graphql.ID }) (*pullRequestResolver, error) {
+	if err := limit(ctx, ratelimit.GroupPullRequests); err != nil {
+		return nil, err
+	}
+
+	pr, err := loadersFrom(ctx).prs.Load(ctx, string(args.ID))()
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	if pr == nil {
+		return nil, nil
+	}
+	return &pullRequestResolver{pr: *pr}, nil
+}
+
+func (r *resolver) PullRequests(ctx context.Context, args struct {
+	Status      *string
+	AuthorID    *graphql.ID
+	ReviewerID  *graphql.ID
+	TeamName    *string
+	CreatedFrom *graphql.Time
+	CreatedTo   *graphql.Time
+	MergedFrom  *graphql.Time
+	MergedTo    *graphql.Time
+	Sort        *string
+	Order       *string
+	Limit       *int32
+	Cursor      *string
+}) (*pullRequestConnection, error) {
+	if err := limit(ctx, ratelimit.GroupDefault); err != nil {
+		return nil, err
+	}
+
+	p, err := page(args.Limit, args.Cursor)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	res, err := r.svcs.PullRequests.ListPRs(ctx, repository.PRFilter{
+		Status:      domain.PullRequestStatus(deref(args.Status)),
+		AuthorID:    string(deref(args.AuthorID)),
+		ReviewerID:  string(deref(args.ReviewerID)),
+		TeamName:    deref(args.TeamName),
+		CreatedFrom: timeArg(args.CreatedFrom),
+		CreatedTo:   timeArg(args.CreatedTo),
+		MergedFrom:  timeArg(args.MergedFrom),
+		MergedTo:    timeArg(args.MergedTo),
+		Sort:        enum(prSorts, args.Sort, repository.PRSortByID),
+		Order:       enum(sortOrders, args.Order, repository.SortAsc),
+		Page:        p,
+	})
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return &pullRequestConnection{items: pullRequests(res.Items), next: res.NextCursor}, nil
+}
+
+func (r *resolver) Stats(ctx context.Context, args struct {
+	From     *graphql.Time
+	To       *graphql.Time
+	TeamName *string
+	Bucket   *string
+}) (*statsResolver, error) {
+	if err := limit(ctx, ratelimit.GroupStats); err != nil {
+		return nil, err
+	}
+
+	f := repository.StatsFilter{
+		From:     timeArg(args.From),
+		To:       timeArg(args.To),
+		TeamName: deref(args.TeamName),
+		Bucket:   enum(buckets, args.Bucket, repository.StatsBucketDay),
+	}
+	stats, err := r.svcs.PullRequests.GetStats(ctx, f)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return &statsResolver{stats: stats, bucket: f.Bucket}, nil
+}
+
+func (r *resolver) CreatePullRequest(ctx context.Context, args struct {
+	Input struct {
+		ID       graphql.ID
+		Name     string
+		AuthorID graphql.ID
+	}
+}) (*pullRequestResolver, error) {
+	if err := limit(ctx, ratelimit.GroupPullRequests); err != nil {
+		return nil, err
+	}
+
+	in := args.Input
+	var v errs.Validator
+	v.ID("input.id", string(in.ID))
+	v.Name("input.name", in.Name)
+	v.Required("input.authorId", string(in.AuthorID))
+	if err := v.Err(); err != nil {
+		return nil, toError(ctx, err)
+	}
+
+	logging.Annotate(ctx, "pr_id", string(in.ID), "user_id", string(in.AuthorID))
+
+	pr, err := r.svcs.PullRequests.Create(ctx, string(in.ID), in.Name, string(in.AuthorID))
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return r.changed(ctx, pr), nil
+}
+
+func (r *resolver) MergePullRequest(ctx context.Context, args struct{ ID graphql.ID }) (*pullRequestResolver, error) {
+	if err := limit(ctx, ratelimit.GroupPullRequests); err != nil {
+		return nil, err
+	}
+
+	logging.Annotate(ctx, "pr_id", string(args.ID))
+
+	pr, err := r.svcs.PullRequests.Merge(ctx, string(args.ID))
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return r.changed(ctx, pr), nil
+}
+
+func (r *resolver) ReassignReviewer(ctx context.Context, args struct {
+	PullRequestID graphql.ID
+	OldUserID     graphql.ID
+}) (*reassignResult, error) {
+	if err := limit(ctx, ratelimit.GroupPullRequests); err != nil {
+		return nil, err
+	}
+
+	logging.Annotate(ctx, "pr_id", string(args.PullRequestID), "user_id", string(args.OldUserID))
+
+	pr, replacedBy, err := r.svcs.PullRequests.Reassign(ctx, string(args.PullRequestID), string(args.OldUserID))
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	// старый ревьювер из PR ушёл, его открытые ревью тоже изменились
+	loadersFrom(ctx).openReviews.Clear(ctx, string(args.OldUserID))
+	return &reassignResult{pr: r.changed(ctx, pr).pr, replacedBy: replacedBy}, nil
+}
+
+// changed кладёт изменённый мутацией PR в кэш загрузчика, чтобы
+// последующие поля того же запроса видели новое состояние.
+func (r *resolver) changed(ctx context.Context, pr *domain.PullRequest) *pullRequestResolver {
+	l := loadersFrom(ctx)
+	l.prs.Clear(ctx, pr.ID).Prime(ctx, pr.ID, pr)
+	for _, id := range pr.AssignedReviewers {
+		l.openReviews.Clear(ctx, id)
+	}
+	return &pullRequestResolver{pr: *pr}
+}
@@ -0,0 +1,51 @@
+// Package graphql — GraphQL API (/graphql) поверх тех же сервисов, что
+// и HTTP/gRPC. Связанные объекты резолверы загружают пачками через
+// dataloader, чтобы вложенные запросы не порождали N+1 обращений к БД.
+package graphql
+
+import (
+	"avito/internal/service"
+	"context"
+	_ "embed"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Ограничения запроса: глубина вложенности и число параллельных резолверов.
+const (
+	maxDepth       = 10
+	maxParallelism = 20
+)
+
+// Request — тело запроса к /graphql.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Schema исполняет запросы GraphQL поверх svcs.
+type Schema struct {
+	schema *graphql.Schema
+	svcs   *service.Services
+}
+
+// NewSchema разбирает schema.graphql и связывает её с резолверами.
+// Расхождение схемы и резолверов — ошибка сборки, поэтому паникует.
+func NewSchema(svcs *service.Services) *Schema {
+	s := graphql.MustParseSchema(schemaSDL, &resolver{svcs: svcs},
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxParallelism(maxParallelism),
+	)
+	return &Schema{schema: s, svcs: svcs}
+}
+
+// Exec исполняет запрос со своими загрузчиками: кэш связанных объектов
+// живёт один запрос. Организация и вызывающий берутся из ctx.
+func (s *Schema) Exec(ctx context.Context, req Request) *graphql.Response {
+	ctx = withLoaders(ctx, newLoaders(s.svcs))
+	return s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
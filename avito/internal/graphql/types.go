@@ -0,0 +1,256 @@
+package graphql
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"cmp"
+	"context"
+	"slices"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Резолверы объектов схемы. Связанные объекты загружаются через loaders
+// текущего запроса, поэтому их поля не обращаются к сервисам по одному.
+
+type teamResolver struct {
+	name string
+}
+
+func (t *teamResolver) Name() string {
+	return t.name
+}
+
+func (t *teamResolver) Members(ctx context.Context) ([]*userResolver, error) {
+	team, err := loadersFrom(ctx).teams.Load(ctx, t.name)()
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	if team == nil {
+		return []*userResolver{}, nil
+	}
+	res := make([]*userResolver, 0, len(team.Members))
+	for _, m := range team.Members {
+		res = append(res, &userResolver{u: domain.User{
+			ID:       m.UserID,
+			Username: m.Username,
+			TeamName: team.TeamName,
+			IsActive: m.IsActive,
+		}})
+	}
+	return res, nil
+}
+
+type userResolver struct {
+	u domain.User
+}
+
+func (u *userResolver) ID() graphql.ID {
+	return graphql.ID(u.u.ID)
+}
+
+func (u *userResolver) Username() string {
+	return u.u.Username
+}
+
+func (u *userResolver) IsActive() bool {
+	return u.u.IsActive
+}
+
+func (u *userResolver) Team() *teamResolver {
+	if u.u.TeamName == "" {
+		return nil
+	}
+	return &teamResolver{name: u.u.TeamName}
+}
+
+func (u *userResolver) OpenReviews(ctx context.Context) ([]*pullRequestResolver, error) {
+	prs, err := loadersFrom(ctx).openReviews.Load(ctx, u.u.ID)()
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	return pullRequests(prs), nil
+}
+
+type pullRequestResolver struct {
+	pr domain.PullRequest
+}
+
+func pullRequests(prs []domain.PullRequest) []*pullRequestResolver {
+	res := make([]*pullRequestResolver, 0, len(prs))
+	for _, pr := range prs {
+		res = append(res, &pullRequestResolver{pr: pr})
+	}
+	return res
+}
+
+func (p *pullRequestResolver) ID() graphql.ID {
+	return graphql.ID(p.pr.ID)
+}
+
+func (p *pullRequestResolver) Name() string {
+	return p.pr.Name
+}
+
+func (p *pullRequestResolver) Status() string {
+	return string(p.pr.Status)
+}
+
+func (p *pullRequestResolver) Author(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, p.pr.AuthorID)
+}
+
+func (p *pullRequestResolver) Reviewers(ctx context.Context) ([]*userResolver, error) {
+	users, err := loadAll(ctx, loadersFrom(ctx).users, p.pr.AssignedReviewers)
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	res := make([]*userResolver, 0, len(users))
+	for _, u := range users {
+		res = append(res, &userResolver{u: *u})
+	}
+	return res, nil
+}
+
+func (p *pullRequestResolver) CreatedAt() *graphql.Time {
+	return optionalTime(p.pr.CreatedAt)
+}
+
+func (p *pullRequestResolver) MergedAt() *graphql.Time {
+	return optionalTime(p.pr.MergedAt)
+}
+
+func (p *pullRequestResolver) MergedBy() *string {
+	if p.pr.MergedBy == "" {
+		return nil
+	}
+	return &p.pr.MergedBy
+}
+
+// loadUser — пользователь по ID или nil, если его нет.
+func loadUser(ctx context.Context, id string) (*userResolver, error) {
+	u, err := loadersFrom(ctx).users.Load(ctx, id)()
+	if err != nil {
+		return nil, toError(ctx, err)
+	}
+	if u == nil {
+		return nil, nil
+	}
+	return &userResolver{u: *u}, nil
+}
+
+type teamConnection struct {
+	items []*teamResolver
+	next  string
+}
+
+func (c *teamConnection) Items() []*teamResolver { return c.items }
+func (c *teamConnection) NextCursor() *string    { return optionalString(c.next) }
+
+type userConnection struct {
+	items []*userResolver
+	next  string
+}
+
+func (c *userConnection) Items() []*userResolver { return c.items }
+func (c *userConnection) NextCursor() *string    { return optionalString(c.next) }
+
+type pullRequestConnection struct {
+	items []*pullRequestResolver
+	next  string
+}
+
+func (c *pullRequestConnection) Items() []*pullRequestResolver { return c.items }
+func (c *pullRequestConnection) NextCursor() *string           { return optionalString(c.next) }
+
+type reassignResult struct {
+	pr         domain.PullRequest
+	replacedBy string
+}
+
+func (r *reassignResult) PullRequest() *pullRequestResolver {
+	return &pullRequestResolver{pr: r.pr}
+}
+
+func (r *reassignResult) ReplacedBy(ctx context.Context) (*userResolver, error) {
+	u, err := loadUser(ctx, r.replacedBy)
+	if err != nil || u != nil {
+		return u, err
+	}
+	return &userResolver{u: domain.User{ID: r.replacedBy}}, nil
+}
+
+type count struct {
+	key string
+	n   int64
+}
+
+func (c count) Key() string  { return c.key }
+func (c count) Count() int32 { return int32(c.n) }
+
+// counts раскладывает счётчики по ключам в список, отсортированный по ключу.
+func counts(m map[string]int64) []count {
+	res := make([]count, 0, len(m))
+	for k, n := range m {
+		res = append(res, count{key: k, n: n})
+	}
+	slices.SortFunc(res, func(a, b count) int { return cmp.Compare(a.key, b.key) })
+	return res
+}
+
+type statsResolver struct {
+	stats  repository.Stats
+	bucket repository.StatsBucket
+}
+
+func (s *statsResolver) Bucket() string {
+	return bucketToEnum[s.bucket]
+}
+
+func (s *statsResolver) PerReviewer() []count { return counts(s.stats.PerReviewer) }
+func (s *statsResolver) PerStatus() []count   { return counts(s.stats.PerStatus) }
+
+func (s *statsResolver) Teams() []*teamStatsResolver {
+	res := make([]*teamStatsResolver, 0, len(s.stats.Teams))
+	for _, t := range s.stats.Teams {
+		res = append(res, &teamStatsResolver{t: t})
+	}
+	return res
+}
+
+type teamStatsResolver struct {
+	t repository.TeamStats
+}
+
+// Team — nil для PR авторов без команды: их статистика собрана в строку
+// с пустым именем команды.
+func (s *teamStatsResolver) Team() *teamResolver {
+	if s.t.TeamName == "" {
+		return nil
+	}
+	return &teamResolver{name: s.t.TeamName}
+}
+
+func (s *teamStatsResolver) PerReviewer() []count        { return counts(s.t.PerReviewer) }
+func (s *teamStatsResolver) PerStatus() []count          { return counts(s.t.PerStatus) }
+func (s *teamStatsResolver) OpenReviewsPerUser() []count { return counts(s.t.OpenReviewsPerUser) }
+
+func (s *teamStatsResolver) InactiveUsersWithReviews() []count {
+	return counts(s.t.InactiveWithReviews)
+}
+
+func (s *teamStatsResolver) Trend() []trendPoint {
+	res := make([]trendPoint, 0, len(s.t.Trend))
+	for _, p := range s.t.Trend {
+		res = append(res, trendPoint{p: p})
+	}
+	return res
+}
+
+type trendPoint struct {
+	p repository.TrendPoint
+}
+
+func (t trendPoint) Period() graphql.Time { return graphql.Time{Time: t.p.Period} }
+func (t trendPoint) Created() int32       { return int32(t.p.Created) }
+func (t trendPoint) Merged() int32        { return int32(t.p.Merged) }
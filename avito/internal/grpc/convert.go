@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/repository"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Преобразования между сообщениями api/reviewer/v1 и доменными типами.
+
+func teamToProto(t *domain.Team) *reviewerv1.Team {
+	members := make([]*reviewerv1.TeamMember, 0, len(t.Members))
+	for _, m := range t.Members {
+		members = append(members, &reviewerv1.TeamMember{
+			UserId:   m.UserID,
+			Username: m.Username,
+			IsActive: m.IsActive,
+		})
+	}
+	return &reviewerv1.Team{TeamName: t.TeamName, Members: members}
+}
+
+func membersFromProto(in []*reviewerv1.TeamMember) []domain.TeamMember {
+	members := make([]domain.TeamMember, 0, len(in))
+	for _, m := range in {
+		members = append(members, domain.TeamMember{
+			UserID:   m.GetUserId(),
+			Username: m.GetUsername(),
+			IsActive: m.GetIsActive(),
+		})
+	}
+	return members
+}
+
+func userToProto(u *domain.User) *reviewerv1.User {
+	return &reviewerv1.User{
+		UserId:   u.ID,
+		Username: u.Username,
+		TeamName: u.TeamName,
+		IsActive: u.IsActive,
+	}
+}
+
+func pullRequestToProto(pr *domain.PullRequest) *reviewerv1.PullRequest {
+	out := &reviewerv1.PullRequest{
+		PullRequestId:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorId:          pr.AuthorID,
+		Status:            statusToProto(pr.Status),
+		AssignedReviewers: pr.AssignedReviewers,
+		MergedBy:          pr.MergedBy,
+	}
+	if pr.CreatedAt != nil {
+		out.CreatedAt = timestamppb.New(*pr.CreatedAt)
+	}
+	if pr.MergedAt != nil {
+		out.MergedAt = timestamppb.New(*pr.MergedAt)
+	}
+	return out
+}
+
+func statusToProto(s domain.PullRequestStatus) reviewerv1.PullRequestStatus {
+	switch s {
+	case domain.PRStatusOpen:
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN
+	case domain.PRStatusMerged:
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED
+	default:
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED
+	}
+}
+
+// statusFromProto — фильтр по статусу; UNSPECIFIED — любой.
+func statusFromProto(s reviewerv1.PullRequestStatus) (domain.PullRequestStatus, error) {
+	switch s {
+	case reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED:
+		return "", nil
+	case reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN:
+		return domain.PRStatusOpen, nil
+	case reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED:
+		return domain.PRStatusMerged, nil
+	default:
+		return "", errs.Invalid("status", "unknown value")
+	}
+}
+
+func orderFromProto(o reviewerv1.SortOrder) (repository.SortOrder, error) {
+	switch o {
+	case reviewerv1.SortOrder_SORT_ORDER_UNSPECIFIED, reviewerv1.SortOrder_SORT_ORDER_ASC:
+		return repository.SortAsc, nil
+	case reviewerv1.SortOrder_SORT_ORDER_DESC:
+		return repository.SortDesc, nil
+	default:
+		return "", errs.Invalid("order", "unknown value")
+	}
+}
+
+// pageFromProto — как parsePage: limit 0 — значение по умолчанию.
+func pageFromProto(limit int32, cursor string) (repository.Page, error) {
+	if limit < 0 {
+		return repository.Page{}, errs.Invalid("limit", "must not be negative")
+	}
+	return repository.Page{Limit: int(limit), Cursor: cursor}, nil
+}
+
+// optionalTime — nil для незаданного Timestamp.
+func optionalTime(field string, ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, errs.Invalid(field, "must be a valid timestamp")
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain — домен ErrorInfo в деталях статуса.
+const errorDomain = "pr-reviewer-service"
+
+// errorCode — код gRPC для кода ошибки. Единственное место, где коды
+// сопоставляются статусам gRPC, как errorStatus для HTTP.
+func errorCode(code errs.ErrorCode) codes.Code {
+	switch code {
+	case errs.CodeBadRequest, errs.CodeValidationFailed, errs.CodeInvalidCursor:
+		return codes.InvalidArgument
+	case errs.CodeUnauthorized:
+		return codes.Unauthenticated
+	case errs.CodeForbidden:
+		return codes.PermissionDenied
+	case errs.CodeNotFound:
+		return codes.NotFound
+	case errs.CodeTeamExists, errs.CodePRExists, errs.CodeOrgExists:
+		return codes.AlreadyExists
+	case errs.CodePRMerged, errs.CodeNotAssigned, errs.CodeNoCandidate,
+		errs.CodeUserInOtherTeam, errs.CodeTeamNotEmpty:
+		return codes.FailedPrecondition
+	case errs.CodeRateLimited, errs.CodePayloadTooLarge:
+		return codes.ResourceExhausted
+	case errs.CodeMethodNotAllowed:
+		return codes.Unimplemented
+	case errs.CodeUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// toStatus переводит ошибку сервиса в статус gRPC. Код ошибки сервиса
+// передаётся в ErrorInfo.reason, ошибки полей — в BadRequest. Прочие
+// ошибки логируются и отдаются как Internal без подробностей.
+func toStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var appErr *errs.AppError
+	if !errors.As(err, &appErr) {
+		logging.FromContext(ctx).ErrorContext(ctx, "request failed", "error", err.Error())
+		appErr = errs.New(errs.CodeInternal, "internal error")
+	}
+	logging.Annotate(ctx, "error_code", string(appErr.Code))
+
+	st := status.New(errorCode(appErr.Code), appErr.Msg)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(appErr.Code), Domain: errorDomain}}
+	if len(appErr.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(appErr.Fields))
+		for _, f := range appErr.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+			})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/auth"
+	"avito/internal/errs"
+	"avito/internal/metrics"
+	"avito/internal/ratelimit"
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// limitUnary — аналог rateLimit HTTP-роутера: ограничивает частоту вызовов
+// методов API. group выбирает группу метода из groups, key — корзину
+// клиента; метод группы без лимитера не ограничивается. При отказе
+// возвращает RESOURCE_EXHAUSTED и заголовок retry-after в секундах.
+func limitUnary(groups ratelimit.Groups, group func(fullMethod string) string, key func(context.Context) string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isAPIMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		g := group(info.FullMethod)
+		l := groups[g]
+		if l == nil {
+			return handler(ctx, req)
+		}
+
+		d := l.Allow(key(ctx))
+		if !d.Allowed {
+			metrics.GRPCRateLimited.WithLabelValues(g).Inc()
+			retry := strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retry))
+			return nil, toStatus(ctx, errs.New(errs.CodeRateLimited, "rate limit exceeded, retry in "+retry+"s"))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ipGroup относит все методы к группе ratelimit.GroupIP.
+func ipGroup(string) string {
+	return ratelimit.GroupIP
+}
+
+// methodGroup — группа лимита метода, как у соответствующего маршрута HTTP:
+// список команд — /teams, остальные методы команд — /team/*.
+func methodGroup(fullMethod string) string {
+	service := func(desc grpc.ServiceDesc) bool {
+		return strings.HasPrefix(fullMethod, "/"+desc.ServiceName+"/")
+	}
+	switch {
+	case fullMethod == reviewerv1.TeamService_ListTeams_FullMethodName:
+		return ratelimit.GroupDefault
+	case service(reviewerv1.TeamService_ServiceDesc):
+		return ratelimit.GroupTeams
+	case service(reviewerv1.PullRequestService_ServiceDesc):
+		return ratelimit.GroupPullRequests
+	case service(reviewerv1.StatsService_ServiceDesc):
+		return ratelimit.GroupStats
+	default:
+		return ratelimit.GroupDefault
+	}
+}
+
+// clientKey — аутентифицированный вызывающий, а без аутентификации —
+// адрес соединения.
+func clientKey(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.Subject
+	}
+	return peerKey(ctx)
+}
+
+// peerKey — IP-адрес клиента из адреса соединения.
+func peerKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "ip:unknown"
+	}
+	addr := p.Addr.String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return "ip:" + host
+}
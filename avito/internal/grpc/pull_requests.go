@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/service"
+	"context"
+)
+
+type pullRequestServer struct {
+	reviewerv1.UnimplementedPullRequestServiceServer
+	svc *service.PullRequestService
+}
+
+func (s *pullRequestServer) CreatePullRequest(ctx context.Context, req *reviewerv1.CreatePullRequestRequest) (*reviewerv1.CreatePullRequestResponse, error) {
+	var v errs.Validator
+	v.ID("pull_request_id", req.GetPullRequestId())
+	v.Name("pull_request_name", req.GetPullRequestName())
+	v.Required("author_id", req.GetAuthorId())
+	if err := v.Err(); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	logging.Annotate(ctx, "pr_id", req.GetPullRequestId(), "user_id", req.GetAuthorId())
+
+	pr, err := s.svc.Create(ctx, req.GetPullRequestId(), req.GetPullRequestName(), req.GetAuthorId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.CreatePullRequestResponse{PullRequest: pullRequestToProto(pr)}, nil
+}
+
+func (s *pullRequestServer) GetPullRequest(ctx context.Context, req *reviewerv1.GetPullRequestRequest) (*reviewerv1.GetPullRequestResponse, error) {
+	if req.GetPullRequestId() == "" {
+		return nil, toStatus(ctx, errs.Invalid("pull_request_id", "is required"))
+	}
+
+	logging.Annotate(ctx, "pr_id", req.GetPullRequestId())
+
+	pr, err := s.svc.Get(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.GetPullRequestResponse{PullRequest: pullRequestToProto(pr)}, nil
+}
+
+func (s *pullRequestServer) MergePullRequest(ctx context.Context, req *reviewerv1.MergePullRequestRequest) (*reviewerv1.MergePullRequestResponse, error) {
+	if req.GetPullRequestId() == "" {
+		return nil, toStatus(ctx, errs.Invalid("pull_request_id", "is required"))
+	}
+
+	logging.Annotate(ctx, "pr_id", req.GetPullRequestId())
+
+	pr, err := s.svc.Merge(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.MergePullRequestResponse{PullRequest: pullRequestToProto(pr)}, nil
+}
+
+func (s *pullRequestServer) ReassignReviewer(ctx context.Context, req *reviewerv1.ReassignReviewerRequest) (*reviewerv1.ReassignReviewerResponse, error) {
+	var v errs.Validator
+	v.Required("pull_request_id", req.GetPullRequestId())
+	v.Required("old_user_id", req.GetOldUserId())
+	if err := v.Err(); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	logging.Annotate(ctx, "pr_id", req.GetPullRequestId(), "user_id", req.GetOldUserId())
+
+	pr, replacedBy, err := s.svc.Reassign(ctx, req.GetPullRequestId(), req.GetOldUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.ReassignReviewerResponse{
+		PullRequest: pullRequestToProto(pr),
+		ReplacedBy:  replacedBy,
+	}, nil
+}
@@ -0,0 +1,288 @@
+// Package grpc — gRPC API сервиса (api/reviewer/v1) поверх тех же сервисов,
+// что и HTTP: аутентификация, организации и ошибки работают так же.
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/auth"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/ratelimit"
+	"avito/internal/service"
+	"avito/internal/tenant"
+	"avito/internal/tracing"
+	"cmp"
+	"context"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Ключи метаданных — те же заголовки, что у HTTP API, в нижнем регистре.
+const (
+	mdAPIKey        = "x-api-key"
+	mdAuthorization = "authorization"
+	mdOrgID         = "x-org-id"
+)
+
+// Options — настройки gRPC-сервера.
+type Options struct {
+	// AuthEnabled требует API-ключ или JWT на всех методах API, кроме
+	// health и reflection.
+	AuthEnabled bool
+	// RateLimits — лимиты частоты вызовов, общие с HTTP-роутером; методы
+	// входят в те же группы, что и соответствующие маршруты.
+	RateLimits ratelimit.Groups
+	// MaxRecvMsgBytes ограничивает размер входящего сообщения, как
+	// MaxBodyBytes — тело HTTP-запроса; 0 — DefaultMaxRecvMsgBytes.
+	MaxRecvMsgBytes int
+}
+
+// DefaultMaxRecvMsgBytes — предельный размер входящего сообщения по
+// умолчанию, тот же, что у тела HTTP-запроса.
+const DefaultMaxRecvMsgBytes = 1 << 20
+
+// Server — gRPC-сервер с сервисами API, health и reflection.
+type Server struct {
+	srv    *grpc.Server
+	health *health.Server
+}
+
+// NewServer регистрирует сервисы API поверх svcs.
+func NewServer(svcs *service.Services, opts Options) *Server {
+	interceptors := []grpc.UnaryServerInterceptor{
+		traceUnary,
+		logUnary,
+		recoverUnary,
+	}
+	// лимит по IP до аутентификации: вызовы без ключа или с неверным
+	// ключом тоже расходуют его
+	interceptors = append(interceptors, limitUnary(opts.RateLimits, ipGroup, peerKey))
+	if opts.AuthEnabled {
+		interceptors = append(interceptors, authenticateUnary(svcs.Auth))
+	}
+	interceptors = append(interceptors,
+		limitUnary(opts.RateLimits, methodGroup, clientKey),
+		resolveTenantUnary(svcs.Orgs),
+	)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.MaxRecvMsgSize(cmp.Or(opts.MaxRecvMsgBytes, DefaultMaxRecvMsgBytes)),
+	)
+
+	reviewerv1.RegisterTeamServiceServer(srv, &teamServer{svc: svcs.Teams})
+	reviewerv1.RegisterUserServiceServer(srv, &userServer{users: svcs.Users, prs: svcs.PullRequests})
+	reviewerv1.RegisterPullRequestServiceServer(srv, &pullRequestServer{svc: svcs.PullRequests})
+	reviewerv1.RegisterStatsServiceServer(srv, &statsServer{svc: svcs.PullRequests})
+
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	reflection.Register(srv)
+
+	return &Server{srv: srv, health: hs}
+}
+
+// Serve принимает соединения на lis до Shutdown.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.srv.Serve(lis)
+}
+
+// SetDraining переводит health в NOT_SERVING, как /readyz при остановке.
+func (s *Server) SetDraining() {
+	s.health.Shutdown()
+}
+
+// Shutdown дожидается завершения текущих вызовов, но не дольше ctx,
+// после чего обрывает оставшиеся.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
+
+// isAPIMethod отделяет методы API от health и reflection: те доступны без
+// аутентификации и не привязаны к организации.
+func isAPIMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/reviewer.v1.")
+}
+
+// traceUnary открывает серверный спан вызова, продолжая трассу из метаданных.
+func traceUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, span := tracing.StartServer(ctx, metadataCarrier(md), info.FullMethod,
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", info.FullMethod),
+	)
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code == codes.Internal || code == codes.Unknown {
+		span.RecordError(err)
+	}
+	return resp, err
+}
+
+// logUnary — аналог logging.Middleware: логгер вызова в контексте
+// и одна итоговая запись с методом, кодом и длительностью.
+func logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	logger := slog.Default().With("method", info.FullMethod)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+	ctx, annotations := logging.WithRequestAttrs(logging.WithLogger(ctx, logger))
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	args := []any{
+		"code", code.String(),
+		"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		args = append(args, "remote_addr", p.Addr.String())
+	}
+	args = append(args, annotations()...)
+
+	lvl := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelWarn
+	}
+	logger.Log(ctx, lvl, "grpc request", args...)
+	return resp, err
+}
+
+// recoverUnary превращает панику обработчика в Internal.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "panic in grpc handler",
+				"panic", p, "stack", string(debug.Stack()))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// authenticateUnary — аналог authenticate: API-ключ или JWT из метаданных
+// x-api-key или authorization: Bearer.
+func authenticateUnary(svc *service.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isAPIMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		secret := apiKeyFromMetadata(md)
+		if secret == "" {
+			return nil, toStatus(ctx, errs.New(errs.CodeUnauthorized, "api key is required"))
+		}
+		p, err := svc.Authenticate(ctx, secret)
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+
+		ctx = auth.WithPrincipal(ctx, p)
+		ctx = logging.With(ctx, "principal", p.Subject, "role", string(p.Role))
+		logging.Annotate(ctx, "principal", p.Subject, "role", string(p.Role))
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.String("enduser.id", p.Subject),
+			attribute.String("enduser.role", string(p.Role)),
+		)
+		return handler(ctx, req)
+	}
+}
+
+func apiKeyFromMetadata(md metadata.MD) string {
+	if v := md.Get(mdAPIKey); len(v) > 0 && v[0] != "" {
+		return v[0]
+	}
+	if v := md.Get(mdAuthorization); len(v) > 0 {
+		scheme, token, ok := strings.Cut(v[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// resolveTenantUnary — аналог resolveTenant: организация вызова по
+// вызывающему или метаданным x-org-id.
+func resolveTenantUnary(svc *service.OrgService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isAPIMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		var requested string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(mdOrgID); len(v) > 0 {
+				requested = v[0]
+			}
+		}
+		orgID, err := svc.Resolve(ctx, requested)
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+
+		ctx = tenant.WithOrg(ctx, orgID)
+		ctx = logging.With(ctx, "org_id", orgID)
+		logging.Annotate(ctx, "org_id", orgID)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("org.id", orgID))
+		return handler(ctx, req)
+	}
+}
+
+// metadataCarrier читает контекст трассировки из метаданных gRPC.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
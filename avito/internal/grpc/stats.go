@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/errs"
+	"avito/internal/repository"
+	"avito/internal/service"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type statsServer struct {
+	reviewerv1.UnimplementedStatsServiceServer
+	svc *service.PullRequestService
+}
+
+func (s *statsServer) GetStats(ctx context.Context, req *reviewerv1.GetStatsRequest) (*reviewerv1.GetStatsResponse, error) {
+	f := repository.StatsFilter{TeamName: req.GetTeamName()}
+	var err error
+	if f.From, err = optionalTime("from", req.GetFrom()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if f.To, err = optionalTime("to", req.GetTo()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	bucket := req.GetBucket()
+	switch bucket {
+	case reviewerv1.StatsBucket_STATS_BUCKET_UNSPECIFIED, reviewerv1.StatsBucket_STATS_BUCKET_DAY:
+		f.Bucket = repository.StatsBucketDay
+		bucket = reviewerv1.StatsBucket_STATS_BUCKET_DAY
+	case reviewerv1.StatsBucket_STATS_BUCKET_WEEK:
+		f.Bucket = repository.StatsBucketWeek
+	case reviewerv1.StatsBucket_STATS_BUCKET_MONTH:
+		f.Bucket = repository.StatsBucketMonth
+	default:
+		return nil, toStatus(ctx, errs.Invalid("bucket", "unknown value"))
+	}
+
+	stats, err := s.svc.GetStats(ctx, f)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	teams := make([]*reviewerv1.TeamStats, 0, len(stats.Teams))
+	for _, t := range stats.Teams {
+		trend := make([]*reviewerv1.TrendPoint, 0, len(t.Trend))
+		for _, p := range t.Trend {
+			trend = append(trend, &reviewerv1.TrendPoint{
+				Period:  timestamppb.New(p.Period),
+				Created: p.Created,
+				Merged:  p.Merged,
+			})
+		}
+		teams = append(teams, &reviewerv1.TeamStats{
+			TeamName:                 t.TeamName,
+			PerReviewer:              t.PerReviewer,
+			PerStatus:                t.PerStatus,
+			OpenReviewsPerUser:       t.OpenReviewsPerUser,
+			InactiveUsersWithReviews: t.InactiveWithReviews,
+			Trend:                    trend,
+		})
+	}
+	return &reviewerv1.GetStatsResponse{
+		PerReviewer: stats.PerReviewer,
+		PerStatus:   stats.PerStatus,
+		Bucket:      bucket,
+		Teams:       teams,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
+	"avito/internal/service"
+	"context"
+)
+
+type teamServer struct {
+	reviewerv1.UnimplementedTeamServiceServer
+	svc *service.TeamService
+}
+
+func (s *teamServer) AddTeam(ctx context.Context, req *reviewerv1.AddTeamRequest) (*reviewerv1.AddTeamResponse, error) {
+	team := domain.Team{
+		TeamName: req.GetTeam().GetTeamName(),
+		Members:  membersFromProto(req.GetTeam().GetMembers()),
+	}
+	var v errs.Validator
+	v.Name("team.team_name", team.TeamName)
+	v.Members("team.members", team.Members)
+	if err := v.Err(); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	logging.Annotate(ctx, "team_name", team.TeamName)
+
+	created, err := s.svc.CreateTeam(ctx, team)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.AddTeamResponse{Team: teamToProto(created)}, nil
+}
+
+func (s *teamServer) GetTeam(ctx context.Context, req *reviewerv1.GetTeamRequest) (*reviewerv1.GetTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, toStatus(ctx, errs.Invalid("team_name", "is required"))
+	}
+
+	logging.Annotate(ctx, "team_name", req.GetTeamName())
+
+	team, err := s.svc.GetTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.GetTeamResponse{Team: teamToProto(team)}, nil
+}
+
+func (s *teamServer) ListTeams(ctx context.Context, req *reviewerv1.ListTeamsRequest) (*reviewerv1.ListTeamsResponse, error) {
+	page, err := pageFromProto(req.GetLimit(), req.GetCursor())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	order, err := orderFromProto(req.GetOrder())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	res, err := s.svc.ListTeams(ctx, repository.TeamFilter{Order: order, Page: page})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	teams := make([]*reviewerv1.TeamSummary, 0, len(res.Items))
+	for _, t := range res.Items {
+		teams = append(teams, &reviewerv1.TeamSummary{
+			TeamName:           t.TeamName,
+			MembersCount:       t.Members,
+			ActiveMembersCount: t.ActiveMembers,
+		})
+	}
+	return &reviewerv1.ListTeamsResponse{Teams: teams, NextCursor: res.NextCursor}, nil
+}
+
+func (s *teamServer) DeactivateTeam(ctx context.Context, req *reviewerv1.DeactivateTeamRequest) (*reviewerv1.DeactivateTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, toStatus(ctx, errs.Invalid("team_name", "is required"))
+	}
+
+	logging.Annotate(ctx, "team_name", req.GetTeamName())
+
+	res, err := s.svc.BulkDeactivateTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.DeactivateTeamResponse{
+		TeamName:            res.TeamName,
+		DeactivatedUsers:    res.DeactivatedUsers,
+		ReassignedReviewers: res.ReassignedReviewers,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	reviewerv1 "avito/api/reviewer/v1"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
+	"avito/internal/service"
+	"context"
+)
+
+type userServer struct {
+	reviewerv1.UnimplementedUserServiceServer
+	users *service.UserService
+	prs   *service.PullRequestService
+}
+
+func (s *userServer) SetIsActive(ctx context.Context, req *reviewerv1.SetIsActiveRequest) (*reviewerv1.SetIsActiveResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, toStatus(ctx, errs.Invalid("user_id", "is required"))
+	}
+
+	logging.Annotate(ctx, "user_id", req.GetUserId())
+
+	user, err := s.users.SetIsActive(ctx, req.GetUserId(), req.GetIsActive())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &reviewerv1.SetIsActiveResponse{User: userToProto(user)}, nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *reviewerv1.ListUsersRequest) (*reviewerv1.ListUsersResponse, error) {
+	f := repository.UserFilter{TeamName: req.GetTeamName()}
+	var err error
+	if f.Page, err = pageFromProto(req.GetLimit(), req.GetCursor()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if f.Order, err = orderFromProto(req.GetOrder()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if req.IsActive != nil {
+		active := req.GetIsActive()
+		f.IsActive = &active
+	}
+	switch req.GetSort() {
+	case reviewerv1.UserSort_USER_SORT_UNSPECIFIED, reviewerv1.UserSort_USER_SORT_USER_ID:
+		f.Sort = repository.UserSortByID
+	case reviewerv1.UserSort_USER_SORT_USERNAME:
+		f.Sort = repository.UserSortByUsername
+	default:
+		return nil, toStatus(ctx, errs.Invalid("sort", "unknown value"))
+	}
+
+	res, err := s.users.ListUsers(ctx, f)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	users := make([]*reviewerv1.User, 0, len(res.Items))
+	for i := range res.Items {
+		users = append(users, userToProto(&res.Items[i]))
+	}
+	return &reviewerv1.ListUsersResponse{Users: users, NextCursor: res.NextCursor}, nil
+}
+
+func (s *userServer) GetReviews(ctx context.Context, req *reviewerv1.GetReviewsRequest) (*reviewerv1.GetReviewsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, toStatus(ctx, errs.Invalid("user_id", "is required"))
+	}
+
+	var f repository.ReviewFilter
+	var err error
+	if f.Status, err = statusFromProto(req.GetStatus()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	switch req.GetSort() {
+	case reviewerv1.ReviewSort_REVIEW_SORT_UNSPECIFIED, reviewerv1.ReviewSort_REVIEW_SORT_CREATED_AT:
+		f.Sort = repository.PRSortByCreatedAt
+	case reviewerv1.ReviewSort_REVIEW_SORT_MERGED_AT:
+		f.Sort = repository.PRSortByMergedAt
+	default:
+		return nil, toStatus(ctx, errs.Invalid("sort", "unknown value"))
+	}
+	if f.Order, err = orderFromProto(req.GetOrder()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if f.Page, err = pageFromProto(req.GetLimit(), req.GetCursor()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if f.Since, err = optionalTime("since", req.GetSince()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if f.Until, err = optionalTime("until", req.GetUntil()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	logging.Annotate(ctx, "user_id", req.GetUserId())
+
+	page, err := s.prs.GetUserReviews(ctx, req.GetUserId(), f)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	prs := make([]*reviewerv1.PullRequest, 0, len(page.Items))
+	for i := range page.Items {
+		prs = append(prs, pullRequestToProto(&page.Items[i]))
+	}
+	byStatus := make(map[string]int64, len(page.TotalByStatus))
+	for status, cnt := range page.TotalByStatus {
+		byStatus[string(status)] = cnt
+	}
+	return &reviewerv1.GetReviewsResponse{
+		UserId:        req.GetUserId(),
+		PullRequests:  prs,
+		Total:         page.Total,
+		TotalByStatus: byStatus,
+		NextCursor:    page.NextCursor,
+	}, nil
+}
@@ -0,0 +1,121 @@
+// Package health реализует пробы /livez и /readyz.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCheckTimeout ограничивает время одной проверки готовности.
+const DefaultCheckTimeout = 2 * time.Second
+
+// Check возвращает ошибку, если зависимость недоступна.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Probe хранит проверки готовности и флаг остановки сервиса.
+type Probe struct {
+	checks   []namedCheck
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewProbe() *Probe {
+	return &Probe{timeout: DefaultCheckTimeout}
+}
+
+// Add регистрирует проверку готовности. Вызывается до старта сервера.
+func (p *Probe) Add(name string, check Check) {
+	p.checks = append(p.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining переводит сервис в режим остановки: /readyz начинает
+// отвечать 503, чтобы балансировщик перестал слать новые запросы.
+func (p *Probe) SetDraining() {
+	p.draining.Store(true)
+}
+
+type checkResult struct {
+	Status     string  `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type report struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// Livez отвечает 200, пока процесс способен обслуживать HTTP;
+// зависимости не проверяются, чтобы сбой БД не приводил к рестарту.
+func (p *Probe) Livez(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, report{Status: "ok"})
+}
+
+// Readyz параллельно выполняет все проверки с таймаутом и отвечает 200,
+// если все прошли, иначе 503. Во время остановки всегда отвечает 503.
+func (p *Probe) Readyz(w http.ResponseWriter, r *http.Request) {
+	res := report{Status: "ok", Checks: p.run(r.Context())}
+	for _, c := range res.Checks {
+		if c.Status != "ok" {
+			res.Status = "fail"
+		}
+	}
+	if p.draining.Load() {
+		res.Status = "draining"
+	}
+
+	status := http.StatusOK
+	if res.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, status, res)
+}
+
+func (p *Probe) run(ctx context.Context) map[string]checkResult {
+	results := make(map[string]checkResult, len(p.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, c := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(checkCtx)
+			res := checkResult{
+				Status:     "ok",
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				res.Status = "fail"
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[c.name] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func writeReport(w http.ResponseWriter, status int, rep report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(rep)
+}
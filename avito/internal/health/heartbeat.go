@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Heartbeat отслеживает фоновую задачу: задача сообщает о каждом запуске
+// через Beat, а Check падает, если успешного запуска не было дольше maxAge.
+type Heartbeat struct {
+	mu          sync.Mutex
+	maxAge      time.Duration
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// NewHeartbeat создаёт Heartbeat; отсчёт maxAge начинается с момента создания,
+// чтобы задача успела выполнить первый запуск.
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	return &Heartbeat{maxAge: maxAge, lastSuccess: time.Now()}
+}
+
+// Beat фиксирует результат очередного запуска задачи.
+func (h *Heartbeat) Beat(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastErr = err
+	if err == nil {
+		h.lastSuccess = time.Now()
+	}
+}
+
+// Check подходит для Probe.Add.
+func (h *Heartbeat) Check(context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	age := time.Since(h.lastSuccess)
+	if age <= h.maxAge {
+		return nil
+	}
+	if h.lastErr != nil {
+		return fmt.Errorf("no successful run for %s: %w", age.Round(time.Second), h.lastErr)
+	}
+	return fmt.Errorf("no successful run for %s", age.Round(time.Second))
+}
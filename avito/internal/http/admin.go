@@ -0,0 +1,32 @@
+package http
+
+import (
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"net/http"
+)
+
+type logLevelBody struct {
+	Level string `json:"level"`
+}
+
+// GET /admin/logLevel
+func getLogLevel(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, logLevelBody{Level: logging.Level().String()})
+}
+
+// PUT /admin/logLevel {"level":"debug"} меняет уровень логирования без
+// перезапуска сервиса.
+func setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var body logLevelBody
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	if err := logging.SetLevel(body.Level); err != nil {
+		respondError(w, r, errs.Invalid("level", "must be debug, info, warn or error"))
+		return
+	}
+	logging.FromContext(r.Context()).InfoContext(r.Context(), "log level changed", "level", logging.Level().String())
+
+	respondJSON(w, http.StatusOK, logLevelBody{Level: logging.Level().String()})
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"avito/internal/auth"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/service"
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// authenticate пропускает запрос только с действующим API-ключом или JWT из
+// заголовка X-API-Key или Authorization: Bearer и кладёт вызывающего
+// в контекст. Права на конкретные действия проверяют сервисы.
+func authenticate(svc *service.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := apiKeyFromRequest(r)
+			if secret == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pr-reviewer-service"`)
+				respondError(w, r, errs.New(errs.CodeUnauthorized, "api key is required"))
+				return
+			}
+
+			p, err := svc.Authenticate(r.Context(), secret)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pr-reviewer-service", error="invalid_token"`)
+				respondError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// withPrincipal кладёт вызывающего в контекст и добавляет его в лог
+// и трассу запроса.
+func withPrincipal(ctx context.Context, p *domain.Principal) context.Context {
+	ctx = auth.WithPrincipal(ctx, p)
+	ctx = logging.With(ctx, "principal", p.Subject, "role", string(p.Role))
+	logging.Annotate(ctx, "principal", p.Subject, "role", string(p.Role))
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("enduser.id", p.Subject),
+		attribute.String("enduser.role", string(p.Role)),
+	)
+	return ctx
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// requirePlatformAdmin ограничивает маршрут администратором платформы:
+// настройки процесса, например /admin/logLevel, общие для всех организаций.
+func requirePlatformAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.IsPlatformAdmin(r.Context()) {
+			respondError(w, r, errs.New(errs.CodeForbidden, "platform admin only"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AuthHandler обслуживает управление API-ключами.
+type AuthHandler struct {
+	svc *service.AuthService
+}
+
+func NewAuthHandler(svc *service.AuthService) *AuthHandler {
+	return &AuthHandler{svc: svc}
+}
+
+type createAPIKeyRequest struct {
+	Name     string      `json:"name"`
+	Role     domain.Role `json:"role"`
+	UserID   string      `json:"user_id"`
+	TeamName string      `json:"team_name"`
+}
+
+type createAPIKeyResponse struct {
+	Key *domain.APIKey `json:"key"`
+	// Secret показывается только в этом ответе.
+	Secret string `json:"secret"`
+}
+
+type revokeAPIKeyRequest struct {
+	ID int64 `json:"id"`
+}
+
+// POST /auth/keys
+func (h *AuthHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req createAPIKeyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	v.Name("name", req.Name)
+	if !req.Role.Valid() {
+		v.Add("role", "must be one of admin, team-lead, member, bot")
+	}
+	if req.Role == domain.RoleTeamLead && req.TeamName == "" {
+		v.Add("team_name", "is required for team-lead keys")
+	}
+	if req.Role == domain.RoleMember && req.UserID == "" {
+		v.Add("user_id", "is required for member keys")
+	}
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "user_id", req.UserID, "team_name", req.TeamName)
+
+	key, secret, err := h.svc.CreateKey(r.Context(), domain.APIKey{
+		Name:     req.Name,
+		Role:     req.Role,
+		UserID:   req.UserID,
+		TeamName: req.TeamName,
+	})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, createAPIKeyResponse{Key: key, Secret: secret})
+}
+
+// GET /auth/keys
+func (h *AuthHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.svc.ListKeys(r.Context())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"keys": keys,
+	})
+}
+
+// POST /auth/keys/revoke
+func (h *AuthHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	var req revokeAPIKeyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.ID <= 0 {
+		respondError(w, r, errs.Invalid("id", "must be a positive integer"))
+		return
+	}
+
+	key, err := h.svc.RevokeKey(r.Context(), req.ID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"key": key,
+	})
+}
+
+// GET /auth/whoami. При выключенной аутентификации principal равен null.
+func (h *AuthHandler) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	respondJSON(w, http.StatusOK, map[string]any{
+		"principal": p,
+	})
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"avito/api"
+	"avito/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5"
+)
+
+// contractStep — запрос сценария и ожидаемый статус ответа.
+type contractStep struct {
+	method string
+	path   string
+	body   string
+	status int
+}
+
+// contractScenario проходит все маршруты NewRouterForTest: v1, /v2
+// и /graphql. Шаги зависят друг от друга: назначение детерминированное,
+// поэтому ревьюверы PR известны заранее (u4 неактивен при создании pr-1,
+// и ревьюверами становятся u2 и u3).
+var contractScenario = []contractStep{
+	{"GET", "/health", "", 200},
+	{"GET", "/livez", "", 200},
+	{"GET", "/readyz", "", 200},
+	{"GET", "/admin/logLevel", "", 200},
+	{"PUT", "/admin/logLevel", `{"level":"info"}`, 200},
+
+	// команды
+	{"POST", "/team/add", `{"team_name":"backend","members":[
+		{"user_id":"u1","username":"Alice","is_active":true},
+		{"user_id":"u2","username":"Bob","is_active":true},
+		{"user_id":"u3","username":"Carol","is_active":true},
+		{"user_id":"u4","username":"Dave","is_active":false}]}`, 201},
+	{"POST", "/team/add", `{"team_name":"frontend","members":[
+		{"user_id":"f1","username":"Eve","is_active":true}]}`, 201},
+	{"POST", "/team/add", `{"team_name":"backend","members":[]}`, 409},
+	{"POST", "/team/add", `{"team_name":"qa"}`, 400},
+	{"GET", "/team/get?team_name=backend", "", 200},
+	{"GET", "/team/get?team_name=missing", "", 404},
+	{"GET", "/team/get", "", 400},
+	{"POST", "/team/addMembers", `{"team_name":"backend","members":[
+		{"user_id":"u5","username":"Frank","is_active":true}]}`, 200},
+	{"POST", "/team/removeMembers", `{"team_name":"backend","user_ids":["u5"]}`, 200},
+	{"GET", "/teams?order=desc", "", 200},
+
+	// пользователи
+	{"GET", "/users?team_name=backend&is_active=true", "", 200},
+
+	// PR v1
+	{"POST", "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`, 201},
+	{"POST", "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"Add search","author_id":"u1"}`, 409},
+	{"POST", "/pullRequest/create", `{"pull_request_id":"pr-x","pull_request_name":"Orphan","author_id":"nobody"}`, 404},
+	{"POST", "/pullRequest/create", `{"pull_request_id":"pr-x"}`, 400},
+	{"POST", "/users/setIsActive", `{"user_id":"u4","is_active":true}`, 200},
+	{"POST", "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"u2"}`, 200},
+	{"POST", "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"u1"}`, 409},
+	{"GET", "/pullRequest/assignments?pull_request_id=pr-1", "", 200},
+	{"GET", "/users/getReview?user_id=u3", "", 200},
+	{"GET", "/pullRequests?status=OPEN&author_id=u1", "", 200},
+
+	// PR v2
+	{"POST", "/v2/pull-requests", `{"pull_request_id":"pr-2","pull_request_name":"Fix login","author_id":"u1"}`, 201},
+	{"GET", "/v2/pull-requests?reviewer_id=u3", "", 200},
+	{"GET", "/v2/pull-requests/pr-2", "", 200},
+	{"GET", "/v2/pull-requests/missing", "", 404},
+	{"PATCH", "/v2/pull-requests/pr-2", `{"pull_request_name":"Fix login flow"}`, 200},
+	{"GET", "/v2/pull-requests/pr-2/assignments", "", 200},
+	{"POST", "/v2/pull-requests/pr-1/reviewers/u3:reassign", "", 200},
+	{"PATCH", "/v2/pull-requests/pr-2", `{"status":"MERGED"}`, 200},
+	{"PATCH", "/v2/pull-requests/pr-2", `{"pull_request_name":"Too late"}`, 409},
+	{"POST", "/pullRequest/merge", `{"pull_request_id":"pr-1"}`, 200},
+	{"POST", "/pullRequest/merge", `{"pull_request_id":"pr-1"}`, 200},
+	{"GET", "/v2/users/u2/reviews?status=MERGED", "", 200},
+
+	// статистика
+	{"GET", "/stats", "", 200},
+	{"GET", "/stats/latency", "", 200},
+	{"GET", "/stats/fairness?team_name=backend&tolerance=0.5", "", 200},
+
+	// GraphQL
+	{"POST", "/graphql", `{"query":"{ team(name: \"backend\") { name members { id username isActive openReviews { id } } } }"}`, 200},
+	{"POST", "/graphql", `{"query":"{ teams { items { name } nextCursor } users(teamName: \"backend\") { items { id team { name } } } }"}`, 200},
+	{"POST", "/graphql", `{"query":"query($id: ID!) { user(id: $id) { id username } pullRequest(id: \"pr-1\") { id status author { id } reviewers { id } mergedAt } }","variables":{"id":"u1"}}`, 200},
+	{"POST", "/graphql", `{"query":"{ pullRequests(status: MERGED) { items { id name } } stats { bucket perStatus { key count } teams { team { name } } } }"}`, 200},
+	{"POST", "/graphql", `{"query":"mutation { createPullRequest(input: {id: \"pr-3\", name: \"Docs\", authorId: \"u1\"}) { id reviewers { id } } }"}`, 200},
+	{"POST", "/graphql", `{"query":"mutation { reassignReviewer(pullRequestId: \"pr-3\", oldUserId: \"u2\") { pullRequest { id } replacedBy { id } } }"}`, 200},
+	{"POST", "/graphql", `{"query":"mutation { mergePullRequest(id: \"pr-3\") { id status } }"}`, 200},
+
+	// изменения состава команд в конце: они меняют ревьюверов выше
+	{"POST", "/team/moveMember", `{"user_id":"u4","to_team_name":"frontend"}`, 200},
+	{"POST", "/team/rename", `{"team_name":"frontend","new_team_name":"web"}`, 200},
+	{"POST", "/team/deactivateUsers", `{"team_name":"web"}`, 200},
+	{"POST", "/team/delete", `{"team_name":"web"}`, 409},
+	{"POST", "/team/delete", `{"team_name":"web","move_members_to":"backend"}`, 200},
+}
+
+// unmountedInTest — маршруты спецификации, которые NewRouterForTest не
+// объявляет: им нужны организации, ключи или журнал событий.
+var unmountedInTest = []string{"/orgs", "/auth/", "/events/stream", "/ws/inbox"}
+
+// chiOnly — маршруты роутера вне спецификации.
+var chiOnly = []string{"/metrics", "/openapi.yml", "/docs"}
+
+var chiParam = regexp.MustCompile(`\{[^}]+\}`)
+
+func newContractRouter(t *testing.T, onDrift DriftFunc) http.Handler {
+	t.Helper()
+	repo := newMemRepo()
+	teams := service.NewTeamService(repo, repo, repo, memKeys{}, repo)
+	users := service.NewUserService(repo)
+	prs := service.NewPullRequestService(repo, repo, repo, repo, service.WithDeterministicAssignment())
+	teams.SetPullRequestService(prs)
+	return NewRouterForTest(teams, users, prs, onDrift)
+}
+
+// TestContract прогоняет сценарий через NewRouterForTest и падает, если
+// запрос или ответ расходится со спецификацией: запрос — по статусу 400
+// вместо ожидаемого, ответ — через DriftFunc.
+func TestContract(t *testing.T) {
+	var drift []error
+	router := newContractRouter(t, func(_ *http.Request, err error) {
+		drift = append(drift, err)
+	})
+
+	for _, st := range contractScenario {
+		drift = drift[:0]
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, newStepRequest(st))
+
+		name := st.method + " " + st.path
+		if rec.Code != st.status {
+			t.Fatalf("%s: status %d, want %d; body: %s", name, rec.Code, st.status, rec.Body)
+		}
+		for _, err := range drift {
+			t.Errorf("%s: response drift: %v", name, err)
+		}
+		if st.path == "/graphql" {
+			var resp struct {
+				Errors []json.RawMessage `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Errors) > 0 {
+				t.Errorf("%s: graphql errors: %s", name, rec.Body)
+			}
+		}
+	}
+}
+
+// TestContractDetectsDrift проверяет сам механизм: ответ, которого нет
+// в спецификации, доходит до DriftFunc.
+func TestContractDetectsDrift(t *testing.T) {
+	var drift []error
+	validate := openapiValidation(true, true, func(_ *http.Request, err error) {
+		drift = append(drift, err)
+	})
+	h := validate(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"state":"ok"}`))
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+	if len(drift) != 1 {
+		t.Fatalf("drift reports = %v, want one", drift)
+	}
+	if !strings.Contains(drift[0].Error(), "status") {
+		t.Fatalf("drift = %q, want the missing field", drift[0])
+	}
+}
+
+// TestContractCoverage следит, чтобы сценарий не отставал от API: каждый
+// его запрос есть в спецификации, каждая операция спецификации и каждый
+// маршрут роутера проходятся хотя бы одним шагом.
+func TestContractCoverage(t *testing.T) {
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	specRouter, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	covered := make(map[string]bool)
+	for _, st := range contractScenario {
+		route, _, err := specRouter.FindRoute(newStepRequest(st))
+		if err != nil {
+			t.Errorf("%s %s: not in the spec: %v", st.method, st.path, err)
+			continue
+		}
+		covered[route.Method+" "+route.Path] = true
+	}
+	for path, item := range doc.Paths.Map() {
+		if hasPrefix(path, unmountedInTest) {
+			continue
+		}
+		for method := range item.Operations() {
+			if !covered[method+" "+path] {
+				t.Errorf("%s %s: no scenario step", method, path)
+			}
+		}
+	}
+
+	routes, ok := newContractRouter(t, nil).(chi.Routes)
+	if !ok {
+		t.Fatal("NewRouterForTest does not return chi.Routes")
+	}
+	err = chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = strings.TrimSuffix(strings.ReplaceAll(route, "/*/", "/"), "/")
+		if slices.Contains(chiOnly, route) {
+			return nil
+		}
+		// {id} — любой сегмент пути; остальное сравнивается буквально
+		literal := regexp.QuoteMeta(chiParam.ReplaceAllString(route, "\x00"))
+		pattern := regexp.MustCompile("^" + strings.ReplaceAll(literal, "\x00", `[^/]+`) + "$")
+		for _, st := range contractScenario {
+			path, _, _ := strings.Cut(st.path, "?")
+			if st.method == method && pattern.MatchString(path) {
+				return nil
+			}
+		}
+		t.Errorf("%s %s: no scenario step", method, route)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newStepRequest(st contractStep) *http.Request {
+	r := httptest.NewRequest(st.method, st.path, strings.NewReader(st.body))
+	if st.body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,24 +2,124 @@ package http
 
 import (
 	"avito/internal/errs"
+	"avito/internal/logging"
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
-type errorBody struct {
+// problemJSON — формат ошибок RFC 7807, который клиент выбирает заголовком
+// Accept: application/problem+json.
+const problemJSON = "application/problem+json"
+
+// errorResponse — ErrorResponse из openapi.yml.
+type errorResponse struct {
 	Error struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+		Details []errs.FieldError `json:"details,omitempty"`
 	} `json:"error"`
 }
 
-func WriteAppError(w http.ResponseWriter, err *errs.AppError, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+// problemResponse — ErrorResponse в формате RFC 7807. code и errors —
+// расширения: тот же код и те же ошибки полей, что в обычном формате.
+type problemResponse struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"code"`
+	Errors    []errs.FieldError `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// errorStatus — HTTP-статус для кода ошибки. Единственное место, где коды
+// сопоставляются статусам.
+func errorStatus(code errs.ErrorCode) int {
+	switch code {
+	case errs.CodeBadRequest, errs.CodeValidationFailed, errs.CodeInvalidCursor:
+		return http.StatusBadRequest
+	case errs.CodeUnauthorized:
+		return http.StatusUnauthorized
+	case errs.CodeForbidden:
+		return http.StatusForbidden
+	case errs.CodeNotFound:
+		return http.StatusNotFound
+	case errs.CodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case errs.CodeTeamExists, errs.CodePRExists, errs.CodeOrgExists,
+		errs.CodePRMerged, errs.CodeNotAssigned, errs.CodeNoCandidate,
+		errs.CodeUserInOtherTeam, errs.CodeTeamNotEmpty:
+		return http.StatusConflict
+	case errs.CodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case errs.CodeRateLimited:
+		return http.StatusTooManyRequests
+	case errs.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondError — единый способ ответить ошибкой. Доменные ошибки попадают
+// в итоговую запись о запросе кодом, остальные логируются целиком
+// и отдаются клиенту как INTERNAL без подробностей.
+func respondError(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *errs.AppError
+	if !errors.As(err, &appErr) {
+		logging.FromContext(r.Context()).ErrorContext(r.Context(), "request failed", "error", err.Error())
+		appErr = errs.New(errs.CodeInternal, "internal error")
+	}
+	logging.Annotate(r.Context(), "error_code", string(appErr.Code))
+
+	status := errorStatus(appErr.Code)
 
-	body := errorBody{}
-	body.Error.Code = string(err.Code)
-	body.Error.Message = err.Msg
+	if acceptsProblem(r) {
+		w.Header().Set("Content-Type", problemJSON)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(problemResponse{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    appErr.Msg,
+			Instance:  r.URL.Path,
+			Code:      string(appErr.Code),
+			Errors:    appErr.Fields,
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		return
+	}
+
+	resp := errorResponse{}
+	resp.Error.Code = string(appErr.Code)
+	resp.Error.Message = appErr.Msg
+	resp.Error.Details = appErr.Fields
+	respondJSON(w, status, resp)
+}
+
+// acceptsProblem сообщает, просит ли клиент ошибки в формате RFC 7807.
+func acceptsProblem(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mt == problemJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// notFound и methodNotAllowed отвечают на неизвестные маршруты в том же
+// формате, что и остальные ошибки, вместо текстовых ответов chi.
+func notFound(w http.ResponseWriter, r *http.Request) {
+	respondError(w, r, errs.New(errs.CodeNotFound, "route "+r.URL.Path+" not found"))
+}
 
-	_ = json.NewEncoder(w).Encode(body)
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	respondError(w, r, errs.New(errs.CodeMethodNotAllowed, "method "+r.Method+" is not allowed for "+r.URL.Path))
 }
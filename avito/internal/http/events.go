@@ -0,0 +1,157 @@
+package http
+
+import (
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
+	"avito/internal/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultEventsHeartbeat — интервал комментариев-пингов в потоке событий
+// по умолчанию.
+const DefaultEventsHeartbeat = 15 * time.Second
+
+const (
+	// replayBatch — сколько событий журнала читается за один запрос.
+	replayBatch = 500
+	// retryMillis — пауза переподключения, которую поток советует клиенту.
+	retryMillis = 3000
+)
+
+type EventHandler struct {
+	svc       *service.EventService
+	heartbeat time.Duration
+}
+
+func NewEventHandler(svc *service.EventService, heartbeat time.Duration) *EventHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultEventsHeartbeat
+	}
+	return &EventHandler{svc: svc, heartbeat: heartbeat}
+}
+
+// Stream: GET /events/stream?user_id=&team_name=&pull_request_id= —
+// Server-Sent Events. С заголовком Last-Event-ID (или параметром
+// last_event_id для первого подключения) поток сначала отдаёт события
+// журнала после него, затем новые. Доставка «хотя бы один раз»: клиент
+// может получить событие повторно и должен сверять id.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := repository.EventFilter{
+		UserID:   q.Get("user_id"),
+		TeamName: q.Get("team_name"),
+		PRID:     q.Get("pull_request_id"),
+	}
+
+	lastID, resume, err := parseLastEventID(r)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	for _, a := range [][2]string{{"user_id", f.UserID}, {"team_name", f.TeamName}, {"pr_id", f.PRID}} {
+		if a[1] != "" {
+			logging.Annotate(r.Context(), a[0], a[1])
+		}
+	}
+
+	// подписка раньше чтения журнала: события, записанные между ними,
+	// придут из подписки, а повторы отсеются по id
+	sub := h.svc.Subscribe(r.Context(), f, 0)
+	defer sub.Close()
+
+	// поток живёт дольше таймаутов чтения и записи сервера
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryMillis); err != nil {
+		return
+	}
+
+	replayed := make(map[int64]struct{})
+	for resume {
+		batch, err := h.svc.Replay(r.Context(), f, lastID, replayBatch)
+		if err != nil {
+			// заголовки уже отправлены: обрываем поток, клиент
+			// переподключится с последним полученным id
+			logging.FromContext(r.Context()).ErrorContext(r.Context(), "replay events", "error", err.Error())
+			return
+		}
+		for _, e := range batch {
+			if writeEvent(w, e) != nil {
+				return
+			}
+			replayed[e.ID] = struct{}{}
+			lastID = e.ID
+		}
+		resume = len(batch) == replayBatch
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				// подписка снята: буфер переполнен или сервер останавливается
+				return
+			}
+			if _, ok := replayed[e.ID]; ok {
+				continue
+			}
+			if writeEvent(w, e) != nil {
+				return
+			}
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// parseLastEventID возвращает id, с которого клиент продолжает поток;
+// resume ложно, если клиент подключается впервые.
+func parseLastEventID(r *http.Request) (id int64, resume bool, err error) {
+	field, v := "Last-Event-ID", r.Header.Get("Last-Event-ID")
+	if v == "" {
+		field, v = "last_event_id", r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return 0, false, nil
+	}
+	id, err = strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, errs.Invalid(field, "must be a non-negative integer")
+	}
+	return id, true, nil
+}
+
+func writeEvent(w http.ResponseWriter, e domain.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
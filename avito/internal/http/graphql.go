@@ -0,0 +1,66 @@
+package http
+
+import (
+	"avito/internal/errs"
+	"avito/internal/graphql"
+	"avito/internal/logging"
+	"avito/internal/metrics"
+	"avito/internal/ratelimit"
+	"avito/internal/service"
+	"context"
+	"net/http"
+)
+
+type GraphQLHandler struct {
+	schema *graphql.Schema
+	limits ratelimit.Groups
+}
+
+// NewGraphQLHandler — обработчик /graphql. limits — лимиты групп
+// маршрутов: корневые поля запроса списываются из корзин тех же групп,
+// что и их аналоги в HTTP API; nil их не ограничивает.
+func NewGraphQLHandler(svcs *service.Services, limits ratelimit.Groups) *GraphQLHandler {
+	return &GraphQLHandler{schema: graphql.NewSchema(svcs), limits: limits}
+}
+
+// Query: POST /graphql. Ошибки разбора тела — обычные ошибки API, ошибки
+// запроса и резолверов — в errors ответа GraphQL со статусом 200.
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Query == "" {
+		respondError(w, r, errs.Invalid("query", "is required"))
+		return
+	}
+
+	if req.OperationName != "" {
+		logging.Annotate(r.Context(), "graphql_operation", req.OperationName)
+	}
+
+	ctx := r.Context()
+	if h.limits != nil {
+		ctx = graphql.WithLimiter(ctx, h.limitField(r))
+	}
+	respondJSON(w, http.StatusOK, h.schema.Exec(ctx, req))
+}
+
+// limitField списывает корневое поле из корзины клиента в группе; отказ
+// становится ошибкой RATE_LIMITED этого поля.
+func (h *GraphQLHandler) limitField(r *http.Request) graphql.Limiter {
+	key := clientKey(r)
+	return func(_ context.Context, group string) error {
+		l := h.limits[group]
+		if l == nil {
+			return nil
+		}
+		d := l.Allow(key)
+		if d.Allowed {
+			return nil
+		}
+		metrics.RateLimited.WithLabelValues(group).Inc()
+		return errs.New(errs.CodeRateLimited,
+			"rate limit of group "+group+" exceeded, retry in "+seconds(d.RetryAfter)+"s")
+	}
+}
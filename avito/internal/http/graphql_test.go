@@ -0,0 +1,55 @@
+package http
+
+import (
+	"avito/internal/domain"
+	"avito/internal/service"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestGraphQLStatsOfAuthorWithoutTeam: статистика PR автора, выведенного
+// из команды, отдаётся с team: null, а не падает на разрешении команды.
+func TestGraphQLStatsOfAuthorWithoutTeam(t *testing.T) {
+	repo := newMemRepo()
+	ctx := context.Background()
+	_ = repo.CreateTeam(ctx, domain.Team{TeamName: "backend", Members: []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+	}})
+	_ = repo.UpsertUser(ctx, domain.User{ID: "u9", Username: "Ghost"})
+	_ = repo.CreatePR(ctx, domain.PullRequest{ID: "pr-1", Name: "Old", AuthorID: "u9", Status: domain.PRStatusOpen})
+	_ = repo.CreatePR(ctx, domain.PullRequest{ID: "pr-2", Name: "New", AuthorID: "u1", Status: domain.PRStatusOpen})
+
+	h := NewGraphQLHandler(&service.Services{
+		Teams:        service.NewTeamService(repo, repo, repo, memKeys{}, repo),
+		PullRequests: service.NewPullRequestService(repo, repo, repo, repo),
+	}, nil)
+	rec := httptest.NewRecorder()
+	h.Query(rec, httptest.NewRequest("POST", "/graphql",
+		strings.NewReader(`{"query":"{ stats { teams { team { name members { id } } perStatus { key count } } } }"}`)))
+
+	var resp struct {
+		Data struct {
+			Stats struct {
+				Teams []struct {
+					Team *struct {
+						Name string `json:"name"`
+					} `json:"team"`
+				} `json:"teams"`
+			} `json:"stats"`
+		} `json:"data"`
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v; body: %s", err, rec.Body)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("graphql errors: %s", rec.Body)
+	}
+	teams := resp.Data.Stats.Teams
+	if len(teams) != 2 || teams[0].Team != nil || teams[1].Team == nil || teams[1].Team.Name != "backend" {
+		t.Fatalf("stats teams = %s, want team null and backend", rec.Body)
+	}
+}
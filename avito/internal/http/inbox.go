@@ -0,0 +1,366 @@
+package http
+
+import (
+	"avito/internal/auth"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/events"
+	"avito/internal/logging"
+	"avito/internal/metrics"
+	"avito/internal/service"
+	"avito/internal/tenant"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Настройки /ws/inbox по умолчанию.
+const (
+	DefaultWebSocketMaxConnections = 1000
+	DefaultWebSocketBufferSize     = 64
+	DefaultWebSocketPingInterval   = 30 * time.Second
+	DefaultWebSocketWriteTimeout   = 10 * time.Second
+)
+
+const (
+	// inboxHandshakeTimeout — сколько клиент может идти от подключения
+	// до подписки.
+	inboxHandshakeTimeout = 10 * time.Second
+	// inboxReadLimit ограничивает сообщение клиента: ему нечего слать,
+	// кроме коротких команд.
+	inboxReadLimit = 4 << 10
+)
+
+// InboxOptions — настройки InboxHandler.
+type InboxOptions struct {
+	// AuthEnabled требует API-ключ или JWT: в заголовках рукопожатия или
+	// первым сообщением auth.
+	AuthEnabled bool
+	// MaxConnections — предел соединений процесса; 0 —
+	// DefaultWebSocketMaxConnections.
+	MaxConnections int
+	// BufferSize — буфер событий соединения; 0 — DefaultWebSocketBufferSize.
+	BufferSize int
+	// PingInterval и WriteTimeout — 0 означает значения по умолчанию.
+	PingInterval time.Duration
+	WriteTimeout time.Duration
+}
+
+// InboxHandler обслуживает WebSocket-подписки на входящие ревью.
+type InboxHandler struct {
+	inbox *service.InboxService
+	auth  *service.AuthService
+	orgs  *service.OrgService
+	opts  InboxOptions
+	// slots — семафор соединений процесса.
+	slots chan struct{}
+}
+
+func NewInboxHandler(inbox *service.InboxService, authSvc *service.AuthService, orgs *service.OrgService, opts InboxOptions) *InboxHandler {
+	if opts.MaxConnections <= 0 {
+		opts.MaxConnections = DefaultWebSocketMaxConnections
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultWebSocketBufferSize
+	}
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = DefaultWebSocketPingInterval
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultWebSocketWriteTimeout
+	}
+	return &InboxHandler{
+		inbox: inbox,
+		auth:  authSvc,
+		orgs:  orgs,
+		opts:  opts,
+		slots: make(chan struct{}, opts.MaxConnections),
+	}
+}
+
+// Типы сообщений /ws/inbox.
+const (
+	inboxMsgAuth      = "auth"
+	inboxMsgSubscribe = "subscribe"
+	inboxMsgPing      = "ping"
+	inboxMsgPong      = "pong"
+	inboxMsgSnapshot  = "snapshot"
+	inboxMsgError     = "error"
+)
+
+// inboxClientMessage — сообщение клиента.
+type inboxClientMessage struct {
+	Type   string `json:"type"`
+	Token  string `json:"token,omitempty"`
+	OrgID  string `json:"org_id,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// inboxServerMessage — сообщение сервера: snapshot, added, removed, pong
+// или error.
+type inboxServerMessage struct {
+	Type         string             `json:"type"`
+	UserID       string             `json:"user_id,omitempty"`
+	PullRequests []pullRequestShort `json:"pull_requests,omitzero"`
+	PullRequest  *pullRequestShort  `json:"pull_request,omitempty"`
+	// EventID и Reason — событие, вызвавшее изменение; пусты у изменений
+	// после пересверки с базой.
+	EventID int64            `json:"event_id,omitempty"`
+	Reason  domain.EventType `json:"reason,omitempty"`
+	Code    errs.ErrorCode   `json:"code,omitempty"`
+	Message string           `json:"message,omitempty"`
+}
+
+// Connect: GET /ws/inbox — WebSocket с входящими ревью пользователя.
+// Клиент аутентифицируется заголовками рукопожатия или первым сообщением
+// {"type":"auth","token":...}, затем подписывается
+// {"type":"subscribe","user_id":...} и получает снимок входящих и далее
+// сообщения added/removed. Ошибки рукопожатия закрывают соединение
+// с кодом 4000 + HTTP-статус ошибки.
+func (h *InboxHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		metrics.WebSocketRejected.Inc()
+		respondError(w, r, errs.New(errs.CodeUnavailable, "too many websocket connections"))
+		return
+	}
+
+	ctx := r.Context()
+	// ключ в заголовках проверяется до переключения протокола, чтобы
+	// отказ был обычным ответом 401
+	if secret := apiKeyFromRequest(r); h.opts.AuthEnabled && secret != "" {
+		p, err := h.auth.Authenticate(ctx, secret)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+		ctx = withPrincipal(ctx, p)
+	}
+
+	// соединение живёт дольше таймаутов чтения и записи сервера
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept уже ответил клиенту
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(inboxReadLimit)
+
+	metrics.WebSocketConnections.Inc()
+	defer metrics.WebSocketConnections.Dec()
+
+	// после переключения протокола контекст запроса не отменяется при
+	// разрыве: его отменяет читающая горутина
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	in, err := h.handshake(ctx, conn, r.Header.Get(tenant.Header))
+	if err != nil {
+		closeWithError(ctx, conn, err)
+		return
+	}
+	defer in.Close()
+
+	snapshot := inboxServerMessage{Type: inboxMsgSnapshot, UserID: in.UserID, PullRequests: []pullRequestShort{}}
+	for _, pr := range in.Items() {
+		snapshot.PullRequests = append(snapshot.PullRequests, toPullRequestShort(pr))
+	}
+	if err := h.write(ctx, conn, snapshot); err != nil {
+		return
+	}
+
+	go h.readLoop(ctx, cancel, conn)
+
+	ticker := time.NewTicker(h.opts.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pctx, pcancel := context.WithTimeout(ctx, h.opts.WriteTimeout)
+			err := conn.Ping(pctx)
+			pcancel()
+			if err != nil {
+				return
+			}
+		case e, ok := <-in.Events():
+			if ok {
+				if ch, changed := in.Apply(e); changed && h.writeChange(ctx, conn, ch) != nil {
+					return
+				}
+				continue
+			}
+			switch err := in.Err(); {
+			case errors.Is(err, events.ErrReset):
+				// события могли потеряться: сверяемся с базой и шлём разницу
+				changes, err := h.inbox.Resync(ctx, in)
+				if err != nil {
+					logging.FromContext(ctx).ErrorContext(ctx, "resync inbox", "error", err.Error())
+					conn.Close(websocket.StatusInternalError, "internal error")
+					return
+				}
+				for _, ch := range changes {
+					if h.writeChange(ctx, conn, ch) != nil {
+						return
+					}
+				}
+			case errors.Is(err, events.ErrOverflow):
+				conn.Close(websocket.StatusTryAgainLater, "slow consumer")
+				return
+			default:
+				conn.Close(websocket.StatusGoingAway, "server shutting down")
+				return
+			}
+		}
+	}
+}
+
+// handshake проводит клиента от подключения до подписки: аутентификация
+// (если её не было в заголовках), выбор организации и открытие входящих.
+func (h *InboxHandler) handshake(ctx context.Context, conn *websocket.Conn, orgID string) (*service.Inbox, error) {
+	ctx, cancel := context.WithTimeout(ctx, inboxHandshakeTimeout)
+	defer cancel()
+
+	msg, err := h.read(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := auth.FromContext(ctx); h.opts.AuthEnabled && !ok {
+		if msg.Type != inboxMsgAuth || msg.Token == "" {
+			return nil, errs.New(errs.CodeUnauthorized, "api key is required")
+		}
+		p, err := h.auth.Authenticate(ctx, msg.Token)
+		if err != nil {
+			return nil, err
+		}
+		ctx = withPrincipal(ctx, p)
+	}
+	if msg.Type == inboxMsgAuth {
+		if msg.OrgID != "" {
+			orgID = msg.OrgID
+		}
+		if msg, err = h.read(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+	if msg.Type != inboxMsgSubscribe {
+		return nil, errs.Invalid("type", "expected subscribe")
+	}
+
+	orgID, err = h.orgs.Resolve(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = tenant.WithOrg(ctx, orgID)
+	logging.Annotate(ctx, "org_id", orgID)
+
+	userID := msg.UserID
+	if p, ok := auth.FromContext(ctx); ok && userID == "" {
+		userID = p.UserID
+	}
+	if userID == "" {
+		return nil, errs.Invalid("user_id", "is required")
+	}
+	logging.Annotate(ctx, "user_id", userID)
+
+	return h.inbox.Open(ctx, userID, h.opts.BufferSize)
+}
+
+// readLoop читает сообщения клиента до разрыва, отвечает на ping
+// и отменяет ctx, когда соединение закрыто. Чтение нужно и для Conn.Ping:
+// понги клиента обрабатываются внутри Read.
+func (h *InboxHandler) readLoop(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	defer cancel()
+	for {
+		msg, err := h.read(ctx, conn)
+		var appErr *errs.AppError
+		switch {
+		case errors.As(err, &appErr):
+			// неверное сообщение не рвёт подписку
+			if h.write(ctx, conn, inboxServerMessage{Type: inboxMsgError, Code: appErr.Code, Message: appErr.Msg}) != nil {
+				return
+			}
+		case err != nil:
+			return
+		case msg.Type == inboxMsgPing:
+			if h.write(ctx, conn, inboxServerMessage{Type: inboxMsgPong}) != nil {
+				return
+			}
+		default:
+			if h.write(ctx, conn, inboxServerMessage{
+				Type:    inboxMsgError,
+				Code:    errs.CodeBadRequest,
+				Message: "unexpected message type " + msg.Type,
+			}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// read читает сообщение клиента. Ошибки разбора — AppError, ошибки
+// соединения возвращаются как есть.
+func (h *InboxHandler) read(ctx context.Context, conn *websocket.Conn) (inboxClientMessage, error) {
+	var msg inboxClientMessage
+	typ, data, err := conn.Read(ctx)
+	if err != nil {
+		return msg, err
+	}
+	if typ != websocket.MessageText || json.Unmarshal(data, &msg) != nil {
+		return msg, errs.New(errs.CodeBadRequest, "message must be a JSON object")
+	}
+	return msg, nil
+}
+
+// write отправляет сообщение; медленный клиент отключается по WriteTimeout.
+func (h *InboxHandler) write(ctx context.Context, conn *websocket.Conn, msg inboxServerMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, h.opts.WriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, conn, msg)
+}
+
+func (h *InboxHandler) writeChange(ctx context.Context, conn *websocket.Conn, ch service.InboxChange) error {
+	pr := toPullRequestShort(ch.PullRequest)
+	msg := inboxServerMessage{Type: string(ch.Kind), PullRequest: &pr}
+	if ch.Event != nil {
+		msg.EventID = ch.Event.ID
+		msg.Reason = ch.Event.Type
+	}
+	return h.write(ctx, conn, msg)
+}
+
+// closeWithError закрывает соединение после неудачного рукопожатия:
+// ошибки сервиса — кодом 4000 + HTTP-статус и их текстом, прочие —
+// как внутренние.
+func closeWithError(ctx context.Context, conn *websocket.Conn, err error) {
+	var appErr *errs.AppError
+	switch {
+	case errors.As(err, &appErr):
+		reason := appErr.Msg
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		conn.Close(websocket.StatusCode(4000+errorStatus(appErr.Code)), reason)
+	case websocket.CloseStatus(err) != -1, errors.Is(err, context.Canceled):
+		// клиент уже закрыл соединение
+	case errors.Is(err, context.DeadlineExceeded):
+		conn.Close(websocket.StatusPolicyViolation, "handshake timeout")
+	default:
+		logging.FromContext(ctx).ErrorContext(ctx, "inbox handshake", "error", err.Error())
+		conn.Close(websocket.StatusInternalError, "internal error")
+	}
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"avito/internal/auth"
+	"avito/internal/errs"
+	"avito/internal/metrics"
+	"avito/internal/ratelimit"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type decisionKey struct{}
+
+// rateLimit ограничивает частоту запросов группы маршрутов group; key
+// выбирает корзину клиента — clientKey или ipKey. Запрос проходит и лимит
+// по IP, и лимит группы: заголовки RateLimit-* описывают более строгое
+// из решений, иначе клиент у предела по IP видел бы остаток группы.
+func rateLimit(group string, l *ratelimit.Limiter, key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			own := l.Allow(key(r))
+			d := own
+			if outer, ok := r.Context().Value(decisionKey{}).(ratelimit.Decision); ok {
+				d = own.Stricter(outer)
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			h.Set("RateLimit-Reset", seconds(d.Reset))
+
+			if !own.Allowed {
+				metrics.RateLimited.WithLabelValues(group).Inc()
+				h.Set("Retry-After", seconds(own.RetryAfter))
+				respondError(w, r, errs.New(errs.CodeRateLimited, "rate limit exceeded, retry in "+seconds(own.RetryAfter)+"s"))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), decisionKey{}, d)))
+		})
+	}
+}
+
+// clientKey — аутентифицированный вызывающий, а без аутентификации —
+// IP-адрес. Поэтому лимиты с ним стоят после authenticate.
+func clientKey(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		return p.Subject
+	}
+	return ipKey(r)
+}
+
+// ipKey — IP-адрес клиента, который middleware.RealIP уже подставил
+// в RemoteAddr.
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds округляет вверх: клиент, подождавший указанное время, не
+// должен снова получить 429.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// maxBodySize ограничивает размер тела запроса; превышение обнаруживается
+// при чтении тела в decodeJSON.
+func maxBodySize(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				respondError(w, r, errs.New(errs.CodePayloadTooLarge,
+					"request body exceeds "+strconv.FormatInt(n, 10)+" bytes"))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// decodeJSON читает тело запроса в v. При ошибке пишет ответ сам
+// через respondError и возвращает false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondError(w, r, errs.New(errs.CodePayloadTooLarge,
+			"request body exceeds "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes"))
+		return false
+	}
+	// неверный тип значения — ошибка поля, остальное — неразборчивое тело
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		respondError(w, r, errs.Invalid(typeErr.Field, "must be "+jsonTypeName(typeErr.Type.Kind())))
+		return false
+	}
+	respondError(w, r, errs.New(errs.CodeBadRequest, "invalid json: "+err.Error()))
+	return false
+}
+
+func jsonTypeName(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"avito/internal/errs"
+	"avito/internal/ratelimit"
+	"avito/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRateLimitHeadersReportStricterLimit: лимит группы стоит внутри
+// лимита по IP, но заголовки описывают тот, что ближе к исчерпанию.
+func TestRateLimitHeadersReportStricterLimit(t *testing.T) {
+	ip := rateLimit(ratelimit.GroupIP, ratelimit.New(ratelimit.Limit{RPS: 0.01, Burst: 3}), ipKey)
+	group := rateLimit(ratelimit.GroupDefault, ratelimit.New(ratelimit.Limit{RPS: 1, Burst: 10}), clientKey)
+	h := ip(group(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+
+	for i, want := range []int{2, 1, 0} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/teams", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d", i, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Limit"); got != "3" {
+			t.Fatalf("request %d: RateLimit-Limit = %s, want the IP limit 3", i, got)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(want) {
+			t.Fatalf("request %d: RateLimit-Remaining = %s, want %d", i, got, want)
+		}
+		// корзина по IP наполняется за сотни секунд, группы — за секунды
+		if reset, _ := strconv.Atoi(rec.Header().Get("RateLimit-Reset")); reset < 100 {
+			t.Fatalf("request %d: RateLimit-Reset = %d, want the IP bucket's", i, reset)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/teams", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d after the IP burst, want 429", rec.Code)
+	}
+}
+
+// TestGraphQLChargesFieldGroups: корневые поля /graphql расходуют корзину
+// своей группы, а не только default.
+func TestGraphQLChargesFieldGroups(t *testing.T) {
+	repo := newMemRepo()
+	prs := service.NewPullRequestService(repo, repo, repo, repo)
+	h := NewGraphQLHandler(&service.Services{PullRequests: prs}, ratelimit.Groups{
+		ratelimit.GroupStats: ratelimit.New(ratelimit.Limit{RPS: 0.01, Burst: 1}),
+	})
+
+	rec := httptest.NewRecorder()
+	h.Query(rec, httptest.NewRequest("POST", "/graphql",
+		strings.NewReader(`{"query":"{ a: stats { bucket } b: stats { bucket } }"}`)))
+
+	var resp struct {
+		Errors []struct {
+			Extensions map[string]string `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v; body: %s", err, rec.Body)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != string(errs.CodeRateLimited) {
+		t.Fatalf("errors = %+v, want one RATE_LIMITED for the second stats field", resp.Errors)
+	}
+}
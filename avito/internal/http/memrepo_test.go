@@ -0,0 +1,466 @@
+package http
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// memRepo — репозитории команд, пользователей и PR в памяти для
+// контрактных тестов: ведут себя как postgres-реализации в пределах
+// одной организации, без курсоров — всё помещается на одну страницу.
+type memRepo struct {
+	teams     map[string]int64
+	nextTeam  int64
+	users     map[string]*domain.User
+	prs       map[string]*domain.PullRequest
+	decisions []domain.AssignmentDecision
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{
+		teams: make(map[string]int64),
+		users: make(map[string]*domain.User),
+		prs:   make(map[string]*domain.PullRequest),
+	}
+}
+
+// WithinTx выполняет fn без транзакции: тесты однопоточные.
+func (m *memRepo) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// команды
+
+func (m *memRepo) CreateTeam(_ context.Context, team domain.Team) error {
+	if _, ok := m.teams[team.TeamName]; ok {
+		return repository.ErrAlreadyExists
+	}
+	m.nextTeam++
+	m.teams[team.TeamName] = m.nextTeam
+	m.upsertMembers(team.TeamName, team.Members)
+	return nil
+}
+
+func (m *memRepo) upsertMembers(teamName string, members []domain.TeamMember) {
+	for _, mb := range members {
+		m.users[mb.UserID] = &domain.User{ID: mb.UserID, Username: mb.Username, TeamName: teamName, IsActive: mb.IsActive}
+	}
+}
+
+func (m *memRepo) GetTeam(_ context.Context, name string) (*domain.Team, error) {
+	id, ok := m.teams[name]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	team := &domain.Team{ID: id, TeamName: name, Members: make([]domain.TeamMember, 0)}
+	for _, u := range m.sortedUsers() {
+		if u.TeamName == name {
+			team.Members = append(team.Members, domain.TeamMember{UserID: u.ID, Username: u.Username, IsActive: u.IsActive})
+		}
+	}
+	return team, nil
+}
+
+func (m *memRepo) GetTeams(ctx context.Context, names []string) ([]domain.Team, error) {
+	teams := make([]domain.Team, 0, len(names))
+	for _, name := range names {
+		if team, err := m.GetTeam(ctx, name); err == nil {
+			teams = append(teams, *team)
+		}
+	}
+	return teams, nil
+}
+
+func (m *memRepo) AddMembers(_ context.Context, teamName string, members []domain.TeamMember) error {
+	m.upsertMembers(teamName, members)
+	return nil
+}
+
+func (m *memRepo) RemoveMembers(_ context.Context, teamName string, userIDs []string) (int64, error) {
+	var n int64
+	for _, id := range userIDs {
+		if u, ok := m.users[id]; ok && u.TeamName == teamName {
+			u.TeamName = ""
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memRepo) MoveMember(_ context.Context, userID, toTeamName string) error {
+	u, ok := m.users[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	u.TeamName = toTeamName
+	return nil
+}
+
+func (m *memRepo) MoveAllMembers(_ context.Context, from, to string) (int64, error) {
+	var n int64
+	for _, u := range m.users {
+		if u.TeamName == from {
+			u.TeamName = to
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memRepo) RenameTeam(_ context.Context, oldName, newName string) error {
+	id, ok := m.teams[oldName]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if _, ok := m.teams[newName]; ok {
+		return repository.ErrAlreadyExists
+	}
+	delete(m.teams, oldName)
+	m.teams[newName] = id
+	for _, u := range m.users {
+		if u.TeamName == oldName {
+			u.TeamName = newName
+		}
+	}
+	return nil
+}
+
+func (m *memRepo) DeleteTeam(_ context.Context, name string) error {
+	if _, ok := m.teams[name]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.teams, name)
+	return nil
+}
+
+func (m *memRepo) ListTeams(_ context.Context, f repository.TeamFilter) (repository.TeamPage, error) {
+	page := repository.TeamPage{Items: make([]repository.TeamSummary, 0, len(m.teams))}
+	for name := range m.teams {
+		s := repository.TeamSummary{TeamName: name}
+		for _, u := range m.users {
+			if u.TeamName == name {
+				s.Members++
+				if u.IsActive {
+					s.ActiveMembers++
+				}
+			}
+		}
+		page.Items = append(page.Items, s)
+	}
+	slices.SortFunc(page.Items, func(a, b repository.TeamSummary) int { return cmp.Compare(a.TeamName, b.TeamName) })
+	if f.Order == repository.SortDesc {
+		slices.Reverse(page.Items)
+	}
+	return page, nil
+}
+
+// memKeys — API-ключи: роутер теста без аутентификации, ключей у команд
+// нет; остальные методы не вызываются.
+type memKeys struct{ repository.APIKeyRepository }
+
+func (memKeys) RevokeTeamKeys(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+// пользователи
+
+func (m *memRepo) sortedUsers() []*domain.User {
+	users := make([]*domain.User, 0, len(m.users))
+	for _, u := range m.users {
+		users = append(users, u)
+	}
+	slices.SortFunc(users, func(a, b *domain.User) int { return cmp.Compare(a.ID, b.ID) })
+	return users
+}
+
+func (m *memRepo) UpsertUser(_ context.Context, u domain.User) error {
+	m.users[u.ID] = &u
+	return nil
+}
+
+func (m *memRepo) GetUser(_ context.Context, userID string) (*domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	res := *u
+	res.TeamID = m.teams[u.TeamName]
+	return &res, nil
+}
+
+func (m *memRepo) GetUsers(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	users := make([]domain.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		if u, err := m.GetUser(ctx, id); err == nil {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (m *memRepo) SetUserActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	u.IsActive = isActive
+	return m.GetUser(ctx, userID)
+}
+
+func (m *memRepo) GetActiveUsersByTeam(_ context.Context, teamName string, excludeIDs []string) ([]domain.User, error) {
+	users := make([]domain.User, 0)
+	for _, u := range m.sortedUsers() {
+		if u.TeamName == teamName && u.IsActive && !slices.Contains(excludeIDs, u.ID) {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (m *memRepo) DeactivateByTeam(_ context.Context, teamName string) (int64, error) {
+	var n int64
+	for _, u := range m.users {
+		if u.TeamName == teamName && u.IsActive {
+			u.IsActive = false
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memRepo) ListUsers(_ context.Context, f repository.UserFilter) (repository.UserPage, error) {
+	page := repository.UserPage{Items: make([]domain.User, 0)}
+	for _, u := range m.sortedUsers() {
+		if f.TeamName != "" && u.TeamName != f.TeamName {
+			continue
+		}
+		if f.IsActive != nil && u.IsActive != *f.IsActive {
+			continue
+		}
+		page.Items = append(page.Items, *u)
+	}
+	return page, nil
+}
+
+// PR
+
+func clonePR(pr *domain.PullRequest) domain.PullRequest {
+	res := *pr
+	res.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+	if res.AssignedReviewers == nil {
+		res.AssignedReviewers = make([]string, 0)
+	}
+	return res
+}
+
+func (m *memRepo) sortedPRs(match func(pr *domain.PullRequest) bool) []domain.PullRequest {
+	prs := make([]domain.PullRequest, 0)
+	for _, pr := range m.prs {
+		if match(pr) {
+			prs = append(prs, clonePR(pr))
+		}
+	}
+	slices.SortFunc(prs, func(a, b domain.PullRequest) int { return cmp.Compare(a.ID, b.ID) })
+	return prs
+}
+
+func (m *memRepo) CreatePR(_ context.Context, pr domain.PullRequest) error {
+	if _, ok := m.prs[pr.ID]; ok {
+		return repository.ErrAlreadyExists
+	}
+	if pr.CreatedAt == nil {
+		now := time.Now().UTC()
+		pr.CreatedAt = &now
+	}
+	res := clonePR(&pr)
+	m.prs[pr.ID] = &res
+	return nil
+}
+
+func (m *memRepo) GetPR(_ context.Context, id string) (*domain.PullRequest, error) {
+	pr, ok := m.prs[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	res := clonePR(pr)
+	return &res, nil
+}
+
+// GetPRForUpdate — GetPR: блокировать нечего, тесты однопоточные.
+func (m *memRepo) GetPRForUpdate(ctx context.Context, id string) (*domain.PullRequest, error) {
+	return m.GetPR(ctx, id)
+}
+
+func (m *memRepo) GetPRs(_ context.Context, ids []string) ([]domain.PullRequest, error) {
+	return m.sortedPRs(func(pr *domain.PullRequest) bool { return slices.Contains(ids, pr.ID) }), nil
+}
+
+func (m *memRepo) UpdatePR(_ context.Context, pr domain.PullRequest) error {
+	if _, ok := m.prs[pr.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	res := clonePR(&pr)
+	m.prs[pr.ID] = &res
+	return nil
+}
+
+func (m *memRepo) GetPRsByReviewer(_ context.Context, userID string, f repository.ReviewFilter) (repository.ReviewPage, error) {
+	page := repository.ReviewPage{TotalByStatus: make(map[domain.PullRequestStatus]int64)}
+	all := m.sortedPRs(func(pr *domain.PullRequest) bool { return slices.Contains(pr.AssignedReviewers, userID) })
+	page.Items = make([]domain.PullRequest, 0, len(all))
+	for _, pr := range all {
+		page.TotalByStatus[pr.Status]++
+		if f.Status == "" || pr.Status == f.Status {
+			page.Items = append(page.Items, pr)
+		}
+	}
+	page.Total = int64(len(page.Items))
+	return page, nil
+}
+
+func (m *memRepo) GetOpenPRsByReviewer(_ context.Context, userID string) ([]domain.PullRequest, error) {
+	return m.sortedPRs(func(pr *domain.PullRequest) bool {
+		return pr.Status == domain.PRStatusOpen && slices.Contains(pr.AssignedReviewers, userID)
+	}), nil
+}
+
+func (m *memRepo) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) (map[string][]domain.PullRequest, error) {
+	res := make(map[string][]domain.PullRequest, len(userIDs))
+	for _, id := range userIDs {
+		res[id], _ = m.GetOpenPRsByReviewer(ctx, id)
+	}
+	return res, nil
+}
+
+func (m *memRepo) GetOpenAssignmentsByTeam(_ context.Context, teamName string) ([]repository.ReviewerAssignment, error) {
+	var res []repository.ReviewerAssignment
+	for _, pr := range m.sortedPRs(func(pr *domain.PullRequest) bool { return pr.Status == domain.PRStatusOpen }) {
+		for _, id := range pr.AssignedReviewers {
+			if u, ok := m.users[id]; ok && u.TeamName == teamName {
+				res = append(res, repository.ReviewerAssignment{PRID: pr.ID, UserID: id})
+			}
+		}
+	}
+	return res, nil
+}
+
+// GetStats считает только суммы по организации и командам, без трендов.
+func (m *memRepo) GetStats(_ context.Context, f repository.StatsFilter) (repository.Stats, error) {
+	stats := repository.Stats{
+		PerReviewer: make(map[string]int64),
+		PerStatus:   make(map[string]int64),
+		Teams:       make([]repository.TeamStats, 0),
+	}
+	teams := make(map[string]*repository.TeamStats)
+	team := func(name string) *repository.TeamStats {
+		if t, ok := teams[name]; ok {
+			return t
+		}
+		t := &repository.TeamStats{
+			TeamName:            name,
+			PerReviewer:         make(map[string]int64),
+			PerStatus:           make(map[string]int64),
+			OpenReviewsPerUser:  make(map[string]int64),
+			InactiveWithReviews: make(map[string]int64),
+			Trend:               make([]repository.TrendPoint, 0),
+		}
+		teams[name] = t
+		return t
+	}
+	for _, pr := range m.sortedPRs(func(*domain.PullRequest) bool { return true }) {
+		author := m.users[pr.AuthorID]
+		if author == nil || (f.TeamName != "" && author.TeamName != f.TeamName) {
+			continue
+		}
+		stats.PerStatus[string(pr.Status)]++
+		team(author.TeamName).PerStatus[string(pr.Status)]++
+		for _, id := range pr.AssignedReviewers {
+			stats.PerReviewer[id]++
+			if r := m.users[id]; r != nil {
+				team(r.TeamName).PerReviewer[id]++
+			}
+		}
+	}
+	for _, t := range teams {
+		stats.Teams = append(stats.Teams, *t)
+	}
+	slices.SortFunc(stats.Teams, func(a, b repository.TeamStats) int { return cmp.Compare(a.TeamName, b.TeamName) })
+	return stats, nil
+}
+
+func (m *memRepo) GetMergeLatency(context.Context, repository.LatencyFilter) (repository.MergeLatency, error) {
+	lat := repository.MergeLatency{
+		PerTeam:   make([]repository.TeamLatency, 0),
+		PerAuthor: make([]repository.AuthorLatency, 0),
+	}
+	for _, pr := range m.sortedPRs(func(pr *domain.PullRequest) bool { return pr.MergedAt != nil }) {
+		author := m.users[pr.AuthorID]
+		if author == nil {
+			continue
+		}
+		secs := pr.MergedAt.Sub(*pr.CreatedAt).Seconds()
+		p := repository.LatencyPercentiles{Count: 1, P50: secs, P90: secs, P99: secs}
+		lat.PerTeam = append(lat.PerTeam, repository.TeamLatency{TeamName: author.TeamName, LatencyPercentiles: p})
+		lat.PerAuthor = append(lat.PerAuthor, repository.AuthorLatency{AuthorID: pr.AuthorID, TeamName: author.TeamName, LatencyPercentiles: p})
+	}
+	return lat, nil
+}
+
+// GetReviewLoad считает каждого участника активным все дни окна.
+func (m *memRepo) GetReviewLoad(_ context.Context, teamName string, from, to time.Time) ([]repository.MemberLoad, error) {
+	loads := make([]repository.MemberLoad, 0)
+	for _, u := range m.sortedUsers() {
+		if u.TeamName != teamName {
+			continue
+		}
+		l := repository.MemberLoad{UserID: u.ID, IsActive: u.IsActive, DaysActive: to.Sub(from).Hours() / 24}
+		for _, pr := range m.prs {
+			if slices.Contains(pr.AssignedReviewers, u.ID) {
+				l.Assignments++
+			}
+		}
+		loads = append(loads, l)
+	}
+	return loads, nil
+}
+
+func (m *memRepo) RecordAssignment(_ context.Context, d domain.AssignmentDecision) error {
+	if d.DecidedAt.IsZero() {
+		d.DecidedAt = time.Now().UTC()
+	}
+	m.decisions = append(m.decisions, d)
+	return nil
+}
+
+func (m *memRepo) GetAssignmentDecisions(_ context.Context, prID string) ([]domain.AssignmentDecision, error) {
+	res := make([]domain.AssignmentDecision, 0)
+	for _, d := range m.decisions {
+		if d.PRID == prID {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func (m *memRepo) ListPRs(_ context.Context, f repository.PRFilter) (repository.PRPage, error) {
+	items := m.sortedPRs(func(pr *domain.PullRequest) bool {
+		switch {
+		case f.Status != "" && pr.Status != f.Status,
+			f.AuthorID != "" && pr.AuthorID != f.AuthorID,
+			f.ReviewerID != "" && !slices.Contains(pr.AssignedReviewers, f.ReviewerID):
+			return false
+		case f.TeamName != "":
+			author := m.users[pr.AuthorID]
+			return author != nil && author.TeamName == f.TeamName
+		}
+		return true
+	})
+	if f.Order == repository.SortDesc {
+		slices.Reverse(items)
+	}
+	return repository.PRPage{Items: items}, nil
+}
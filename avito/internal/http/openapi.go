@@ -0,0 +1,299 @@
+package http
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"avito/api"
+	"avito/internal/errs"
+	"avito/internal/logging"
+)
+
+// serveSpec: GET /openapi.yml — встроенная спецификация API.
+func serveSpec(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(api.Spec)
+}
+
+// swaggerUI — страница /docs; сам Swagger UI грузится с CDN.
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>PR Reviewer Assignment Service — API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.yml", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// serveDocs: GET /docs — Swagger UI по /openapi.yml.
+func serveDocs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.WriteString(w, swaggerUI)
+}
+
+// DriftFunc получает расхождение ответа со спецификацией: err описывает
+// метод, маршрут, статус и нарушенные поля.
+type DriftFunc func(r *http.Request, err error)
+
+// openapiValidation проверяет запросы и ответы по api/openapi.yml.
+// Запрос, не соответствующий спецификации, отклоняется до хендлера
+// (requests); ответ, не соответствующий ей, передаётся в onDrift, а без
+// него пишется в лог (responses); клиент получает ответ без изменений.
+// Маршруты, которых нет в спецификации, пропускаются как есть.
+func openapiValidation(requests, responses bool, onDrift DriftFunc) func(http.Handler) http.Handler {
+	if !requests && !responses {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	// спецификация встроена в бинарник: ошибка здесь — ошибка сборки
+	doc, err := api.Load()
+	if err != nil {
+		panic(err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic("openapi router: " + err.Error())
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError: true,
+					// права проверяют authenticate и сервисы
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			if requests {
+				if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+					respondError(w, r, requestValidationError(err))
+					return
+				}
+			}
+			if !responses || streaming(route) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var body bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&body)
+			next.ServeHTTP(ww, r)
+
+			checkResponse(r, route, input, ww, &body, onDrift)
+		})
+	}
+}
+
+// streaming сообщает, что маршрут отвечает потоком text/event-stream или
+// переключается на WebSocket: такой ответ не копируется в память и со
+// спецификацией не сверяется.
+func streaming(route *routers.Route) bool {
+	if route.Operation == nil || route.Operation.Responses == nil {
+		return false
+	}
+	if route.Operation.Responses.Status(http.StatusSwitchingProtocols) != nil {
+		return true
+	}
+	ok := route.Operation.Responses.Status(http.StatusOK)
+	return ok != nil && ok.Value != nil && ok.Value.Content.Get("text/event-stream") != nil
+}
+
+// checkResponse сверяет записанный ответ со спецификацией и отдаёт
+// расхождение onDrift или пишет его в лог.
+func checkResponse(
+	r *http.Request,
+	route *routers.Route,
+	input *openapi3filter.RequestValidationInput,
+	ww middleware.WrapResponseWriter,
+	body *bytes.Buffer,
+	onDrift DriftFunc,
+) {
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	err := openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 ww.Header(),
+		Body:                   io.NopCloser(body),
+		Options: &openapi3filter.Options{
+			MultiError:            true,
+			IncludeResponseStatus: true,
+		},
+	})
+	if err == nil {
+		return
+	}
+	if onDrift != nil {
+		onDrift(r, fmt.Errorf("%s %s: status %d: %s", r.Method, route.Path, status, responseDrift(err)))
+		return
+	}
+	logging.FromContext(r.Context()).WarnContext(r.Context(), "openapi response drift",
+		"method", r.Method,
+		"route", route.Path,
+		"status", status,
+		"error", responseDrift(err),
+	)
+}
+
+// responseDrift — краткое описание расхождения ответа: поля и причины
+// без дампа схем, который kin-openapi кладёт в текст ошибки.
+func responseDrift(err error) string {
+	var respErr *openapi3filter.ResponseError
+	if !errors.As(err, &respErr) {
+		return err.Error()
+	}
+	schemaErrs := schemaErrors(respErr.Err)
+	if len(schemaErrs) == 0 {
+		return respErr.Reason
+	}
+	msg := make([]string, 0, len(schemaErrs))
+	for _, se := range schemaErrs {
+		msg = append(msg, fieldPath("", se.JSONPointer())+": "+schemaReason(se))
+	}
+	return strings.Join(msg, "; ")
+}
+
+// requestValidationError переводит ошибки kin-openapi в AppError:
+// нарушения схемы параметров и тела — VALIDATION_FAILED с ошибками полей,
+// неразборчивое тело — BAD_REQUEST, слишком большое — PAYLOAD_TOO_LARGE.
+func requestValidationError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errs.New(errs.CodePayloadTooLarge,
+			"request body exceeds "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes")
+	}
+
+	var v errs.Validator
+	for _, e := range flatten(err) {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(e, &reqErr) {
+			return errs.New(errs.CodeBadRequest, e.Error())
+		}
+
+		field := "body"
+		if reqErr.Parameter != nil {
+			field = reqErr.Parameter.Name
+		}
+		schemaErrs := schemaErrors(reqErr.Err)
+		switch {
+		case errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired):
+			v.Add(field, "is required")
+		case len(schemaErrs) > 0:
+			base := ""
+			if reqErr.Parameter != nil {
+				base = field
+			}
+			for _, se := range schemaErrs {
+				v.Add(fieldPath(base, se.JSONPointer()), "%s", schemaReason(se))
+			}
+		case reqErr.Parameter != nil:
+			v.Add(field, "%s", cmp.Or(reqErr.Reason, errorText(reqErr.Err)))
+		default:
+			// тело не разобрано или Content-Type не из спецификации
+			var parseErr *openapi3filter.ParseError
+			if errors.As(reqErr.Err, &parseErr) {
+				return errs.New(errs.CodeBadRequest, "invalid json: "+parseErr.Error())
+			}
+			return errs.New(errs.CodeBadRequest, reqErr.Error())
+		}
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+	return errs.New(errs.CodeBadRequest, err.Error())
+}
+
+// schemaReason — причина нарушения схемы; отсутствие обязательного поля
+// описывается так же, как в errs.Validator.
+func schemaReason(se *openapi3.SchemaError) string {
+	if se.SchemaField == "required" {
+		return "is required"
+	}
+	return se.Reason
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return "is invalid"
+	}
+	return err.Error()
+}
+
+// flatten раскрывает вложенные openapi3.MultiError. Обёртки вроде
+// RequestError не разворачиваются: в них поле или параметр ошибки.
+func flatten(err error) []error {
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+	var out []error
+	for _, e := range multi {
+		out = append(out, flatten(e)...)
+	}
+	return out
+}
+
+func schemaErrors(err error) []*openapi3.SchemaError {
+	var out []*openapi3.SchemaError
+	for _, e := range flatten(err) {
+		var se *openapi3.SchemaError
+		if e != nil && errors.As(e, &se) {
+			out = append(out, se)
+		}
+	}
+	return out
+}
+
+// fieldPath собирает имя поля в формате FieldError: members[1].user_id.
+func fieldPath(base string, pointer []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, p := range pointer {
+		if _, err := strconv.Atoi(p); err == nil {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	if b.Len() == 0 {
+		return "body"
+	}
+	return b.String()
+}
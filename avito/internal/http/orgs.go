@@ -0,0 +1,110 @@
+package http
+
+import (
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/service"
+	"avito/internal/tenant"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// resolveTenant определяет организацию запроса по вызывающему или заголовку
+// X-Org-ID и кладёт её в контекст; все репозитории фильтруют по ней.
+// Стоит после authenticate.
+func resolveTenant(svc *service.OrgService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			orgID, err := svc.Resolve(r.Context(), r.Header.Get(tenant.Header))
+			if err != nil {
+				respondError(w, r, err)
+				return
+			}
+
+			ctx := tenant.WithOrg(r.Context(), orgID)
+			ctx = logging.With(ctx, "org_id", orgID)
+			logging.Annotate(ctx, "org_id", orgID)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("org.id", orgID))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// OrgHandler обслуживает управление организациями.
+type OrgHandler struct {
+	svc *service.OrgService
+}
+
+func NewOrgHandler(svc *service.OrgService) *OrgHandler {
+	return &OrgHandler{svc: svc}
+}
+
+type createOrgRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// POST /orgs
+func (h *OrgHandler) CreateOrg(w http.ResponseWriter, r *http.Request) {
+	var req createOrgRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	if v.Required("id", req.ID) && !tenant.ValidID(req.ID) {
+		v.Add("id", "must be 1-63 lowercase latin letters, digits or '-' and start with a letter or digit")
+	}
+	v.Name("name", req.Name)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "org_id", req.ID)
+
+	org, err := h.svc.CreateOrg(r.Context(), domain.Organization{ID: req.ID, Name: req.Name})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]any{
+		"organization": org,
+	})
+}
+
+// GET /orgs
+func (h *OrgHandler) ListOrgs(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.svc.ListOrgs(r.Context())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"organizations": orgs,
+	})
+}
+
+// GET /orgs/get?id=...
+func (h *OrgHandler) GetOrg(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		respondError(w, r, errs.Invalid("id", "is required"))
+		return
+	}
+
+	org, err := h.svc.GetOrg(r.Context(), id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"organization": org,
+	})
+}
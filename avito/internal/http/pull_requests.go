@@ -3,10 +3,12 @@ package http
 import (
 	"avito/internal/domain"
 	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
 	"avito/internal/service"
 	"encoding/json"
-	"errors"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -27,6 +29,14 @@ type createPullRequestRequest struct {
 	AuthorID        string `json:"author_id"`
 }
 
+func (req createPullRequestRequest) validate() error {
+	var v errs.Validator
+	v.ID("pull_request_id", req.PullRequestID)
+	v.Name("pull_request_name", req.PullRequestName)
+	v.Required("author_id", req.AuthorID)
+	return v.Err()
+}
+
 type mergePullRequestRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
@@ -50,9 +60,27 @@ type pullRequestShort struct {
 	MergedAt        *string `json:"mergedAt,omitempty"`
 }
 
+func toPullRequestShort(pr domain.PullRequest) pullRequestShort {
+	item := pullRequestShort{
+		PullRequestID:   pr.ID,
+		PullRequestName: pr.Name,
+		AuthorID:        pr.AuthorID,
+		Status:          string(pr.Status),
+		CreatedAt:       pr.CreatedAt.Format(time.RFC3339),
+	}
+	if pr.MergedAt != nil {
+		s := pr.MergedAt.Format(time.RFC3339)
+		item.MergedAt = &s
+	}
+	return item
+}
+
 type getUserReviewsResponse struct {
-	UserID       string             `json:"user_id"`
-	PullRequests []pullRequestShort `json:"pull_requests"`
+	UserID        string             `json:"user_id"`
+	PullRequests  []pullRequestShort `json:"pull_requests"`
+	Total         int64              `json:"total"`
+	TotalByStatus map[string]int64   `json:"total_by_status"`
+	NextCursor    string             `json:"next_cursor,omitempty"`
 }
 
 func respondJSON(w http.ResponseWriter, status int, v any) {
@@ -61,58 +89,22 @@ func respondJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-// ErrorResponse из openapi.yml
-type errorResponse struct {
-	Error struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
-	} `json:"error"`
-}
-
-func respondError(w http.ResponseWriter, err error) {
-	var appErr *errs.AppError
-
-	if errors.As(err, &appErr) {
-		resp := errorResponse{}
-		resp.Error.Code = string(appErr.Code)
-		resp.Error.Message = appErr.Error()
-
-		switch appErr.Code {
-		case errs.CodeNotFound:
-			respondJSON(w, http.StatusNotFound, resp)
-		case errs.CodeTeamExists:
-			respondJSON(w, http.StatusBadRequest, resp)
-		case errs.CodePRExists:
-			respondJSON(w, http.StatusConflict, resp)
-		case errs.CodePRMerged, errs.CodeNotAssigned, errs.CodeNoCandidate:
-			respondJSON(w, http.StatusConflict, resp)
-		default:
-			respondJSON(w, http.StatusInternalServerError, resp)
-		}
-		return
-	}
-
-	resp := errorResponse{}
-	resp.Error.Code = "INTERNAL"
-	resp.Error.Message = "internal error"
-	respondJSON(w, http.StatusInternalServerError, resp)
-}
-
 // Create: POST /pullRequest/create.
 func (h *PullRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req createPullRequestRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	if req.PullRequestID == "" || req.PullRequestName == "" || req.AuthorID == "" {
-		http.Error(w, "missing required fields", http.StatusBadRequest)
+	if err := req.validate(); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
+	logging.Annotate(r.Context(), "pr_id", req.PullRequestID, "user_id", req.AuthorID)
+
 	pr, err := h.svc.Create(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -128,18 +120,21 @@ func (h *PullRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
 // Merge: POST /pullRequest/merge.
 func (h *PullRequestHandler) Merge(w http.ResponseWriter, r *http.Request) {
 	var req mergePullRequestRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	if req.PullRequestID == "" {
-		http.Error(w, "pull_request_id is required", http.StatusBadRequest)
+	var v errs.Validator
+	v.Required("pull_request_id", req.PullRequestID)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
+	logging.Annotate(r.Context(), "pr_id", req.PullRequestID)
+
 	pr, err := h.svc.Merge(r.Context(), req.PullRequestID)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -155,18 +150,22 @@ func (h *PullRequestHandler) Merge(w http.ResponseWriter, r *http.Request) {
 // Reassign: POST /pullRequest/reassign.
 func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
 	var req reassignPullRequestRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
-	if req.PullRequestID == "" || req.OldUserID == "" {
-		http.Error(w, "pull_request_id and old_user_id are required", http.StatusBadRequest)
+	var v errs.Validator
+	v.Required("pull_request_id", req.PullRequestID)
+	v.Required("old_user_id", req.OldUserID)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
+	logging.Annotate(r.Context(), "pr_id", req.PullRequestID, "user_id", req.OldUserID)
+
 	pr, replacedBy, err := h.svc.Reassign(r.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -177,49 +176,133 @@ func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, resp)
 }
 
-// GetUserReviews: GET /users/getReview?user_id=....
+// GetUserReviews: GET /users/getReview?user_id=...&status=&since=&until=
+// &sort=created_at|merged_at&order=&limit=&cursor=.
 func (h *PullRequestHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
+	q := r.URL.Query()
+	userID := q.Get("user_id")
 	if userID == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+		respondError(w, r, errs.Invalid("user_id", "is required"))
+		return
+	}
+	h.userReviews(w, r, userID)
+}
+
+// userReviews отвечает страницей PR, где userID — ревьювер; фильтры,
+// сортировка и пагинация — из query.
+func (h *PullRequestHandler) userReviews(w http.ResponseWriter, r *http.Request, userID string) {
+	q := r.URL.Query()
+	var f repository.ReviewFilter
+	var err error
+	if f.Status, err = parsePRStatus(q); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	f.Sort, err = parsePRSort(q, repository.PRSortByCreatedAt,
+		repository.PRSortByCreatedAt, repository.PRSortByMergedAt)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.Order, err = parseOrder(q); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.Page, err = parsePage(q); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.Since, err = parseOptionalTime(q, "since"); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.Until, err = parseOptionalTime(q, "until"); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	prs, err := h.svc.GetUserReviews(r.Context(), userID)
+	logging.Annotate(r.Context(), "user_id", userID)
+
+	page, err := h.svc.GetUserReviews(r.Context(), userID, f)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
-	out := make([]pullRequestShort, 0, len(prs))
-	for _, pr := range prs {
-		item := pullRequestShort{
-			PullRequestID:   pr.ID,
-			PullRequestName: pr.Name,
-			AuthorID:        pr.AuthorID,
-			Status:          string(pr.Status),
-			CreatedAt:       pr.CreatedAt.Format(time.RFC3339),
-		}
+	out := make([]pullRequestShort, 0, len(page.Items))
+	for _, pr := range page.Items {
+		out = append(out, toPullRequestShort(pr))
+	}
 
-		if pr.MergedAt != nil {
-			s := pr.MergedAt.Format(time.RFC3339)
-			item.MergedAt = &s
-		}
-		out = append(out, item)
+	byStatus := make(map[string]int64, len(page.TotalByStatus))
+	for status, cnt := range page.TotalByStatus {
+		byStatus[string(status)] = cnt
 	}
 
 	resp := getUserReviewsResponse{
-		UserID:       userID,
-		PullRequests: out,
+		UserID:        userID,
+		PullRequests:  out,
+		Total:         page.Total,
+		TotalByStatus: byStatus,
+		NextCursor:    page.NextCursor,
 	}
 
 	respondJSON(w, http.StatusOK, resp)
 }
 
-// StatsResponse возвращает простую статистику по назначениям и статусам.
+type getAssignmentDecisionsResponse struct {
+	PullRequestID string                      `json:"pull_request_id"`
+	Decisions     []domain.AssignmentDecision `json:"decisions"`
+}
+
+// GetAssignmentDecisions: GET /pullRequest/assignments?pull_request_id=....
+func (h *PullRequestHandler) GetAssignmentDecisions(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, r, errs.Invalid("pull_request_id", "is required"))
+		return
+	}
+	h.assignmentDecisions(w, r, prID)
+}
+
+func (h *PullRequestHandler) assignmentDecisions(w http.ResponseWriter, r *http.Request, prID string) {
+	logging.Annotate(r.Context(), "pr_id", prID)
+
+	decisions, err := h.svc.GetAssignmentDecisions(r.Context(), prID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	resp := getAssignmentDecisionsResponse{
+		PullRequestID: prID,
+		Decisions:     decisions,
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// StatsResponse возвращает статистику по назначениям и статусам:
+// суммарно по организации и с разбивкой по командам.
 type StatsResponse struct {
-	PerReviewer map[string]int64 `json:"per_reviewer"`
-	PerStatus   map[string]int64 `json:"per_status"`
+	PerReviewer map[string]int64    `json:"per_reviewer"`
+	PerStatus   map[string]int64    `json:"per_status"`
+	Bucket      string              `json:"bucket"`
+	Teams       []teamStatsResponse `json:"teams"`
+}
+
+type teamStatsResponse struct {
+	TeamName                 string               `json:"team_name"`
+	PerReviewer              map[string]int64     `json:"per_reviewer"`
+	PerStatus                map[string]int64     `json:"per_status"`
+	OpenReviewsPerUser       map[string]int64     `json:"open_reviews_per_user"`
+	InactiveUsersWithReviews map[string]int64     `json:"inactive_users_with_reviews"`
+	Trend                    []trendPointResponse `json:"trend"`
+}
+
+type trendPointResponse struct {
+	Period  string `json:"period"`
+	Created int64  `json:"created"`
+	Merged  int64  `json:"merged"`
 }
 
 type StatsHandler struct {
@@ -230,17 +313,289 @@ func NewStatsHandler(prSvc *service.PullRequestService) *StatsHandler {
 	return &StatsHandler{prSvc: prSvc}
 }
 
-// GetStats: GET /stats.
+// GetStats: GET /stats?from=&to=&team_name=&bucket=day|week|month.
 func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.prSvc.GetStats(r.Context())
+	q := r.URL.Query()
+
+	f := repository.StatsFilter{TeamName: q.Get("team_name")}
+	var err error
+	if f.From, err = parseOptionalTime(q, "from"); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.To, err = parseOptionalTime(q, "to"); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	switch bucket := repository.StatsBucket(q.Get("bucket")); bucket {
+	case "":
+		f.Bucket = repository.StatsBucketDay
+	case repository.StatsBucketDay, repository.StatsBucketWeek, repository.StatsBucketMonth:
+		f.Bucket = bucket
+	default:
+		respondError(w, r, errs.Invalid("bucket", "must be day, week or month"))
+		return
+	}
+
+	stats, err := h.prSvc.GetStats(r.Context(), f)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
+	teams := make([]teamStatsResponse, 0, len(stats.Teams))
+	for _, t := range stats.Teams {
+		trend := make([]trendPointResponse, 0, len(t.Trend))
+		for _, p := range t.Trend {
+			trend = append(trend, trendPointResponse{
+				Period:  p.Period.Format(time.RFC3339),
+				Created: p.Created,
+				Merged:  p.Merged,
+			})
+		}
+		teams = append(teams, teamStatsResponse{
+			TeamName:                 t.TeamName,
+			PerReviewer:              t.PerReviewer,
+			PerStatus:                t.PerStatus,
+			OpenReviewsPerUser:       t.OpenReviewsPerUser,
+			InactiveUsersWithReviews: t.InactiveWithReviews,
+			Trend:                    trend,
+		})
+	}
+
 	resp := StatsResponse{
 		PerReviewer: stats.PerReviewer,
 		PerStatus:   stats.PerStatus,
+		Bucket:      string(f.Bucket),
+		Teams:       teams,
 	}
 	respondJSON(w, http.StatusOK, resp)
 }
+
+type latencyResponse struct {
+	Count      int64   `json:"merged_count"`
+	P50Seconds float64 `json:"p50_seconds"`
+	P90Seconds float64 `json:"p90_seconds"`
+	P99Seconds float64 `json:"p99_seconds"`
+}
+
+type teamLatencyResponse struct {
+	TeamName string `json:"team_name"`
+	latencyResponse
+}
+
+type authorLatencyResponse struct {
+	AuthorID string `json:"author_id"`
+	TeamName string `json:"team_name"`
+	latencyResponse
+}
+
+type mergeLatencyResponse struct {
+	PerTeam   []teamLatencyResponse   `json:"per_team"`
+	PerAuthor []authorLatencyResponse `json:"per_author"`
+}
+
+func newLatencyResponse(p repository.LatencyPercentiles) latencyResponse {
+	return latencyResponse{
+		Count:      p.Count,
+		P50Seconds: p.P50,
+		P90Seconds: p.P90,
+		P99Seconds: p.P99,
+	}
+}
+
+// GetMergeLatency: GET /stats/latency?from=&to=&team_name=.
+// Окно задаётся по дате merge.
+func (h *StatsHandler) GetMergeLatency(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	f := repository.LatencyFilter{TeamName: q.Get("team_name")}
+	var err error
+	if f.From, err = parseOptionalTime(q, "from"); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.To, err = parseOptionalTime(q, "to"); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	lat, err := h.prSvc.GetMergeLatency(r.Context(), f)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	resp := mergeLatencyResponse{
+		PerTeam:   make([]teamLatencyResponse, 0, len(lat.PerTeam)),
+		PerAuthor: make([]authorLatencyResponse, 0, len(lat.PerAuthor)),
+	}
+	for _, t := range lat.PerTeam {
+		resp.PerTeam = append(resp.PerTeam, teamLatencyResponse{
+			TeamName:        t.TeamName,
+			latencyResponse: newLatencyResponse(t.LatencyPercentiles),
+		})
+	}
+	for _, a := range lat.PerAuthor {
+		resp.PerAuthor = append(resp.PerAuthor, authorLatencyResponse{
+			AuthorID:        a.AuthorID,
+			TeamName:        a.TeamName,
+			latencyResponse: newLatencyResponse(a.LatencyPercentiles),
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+type memberFairnessResponse struct {
+	UserID      string  `json:"user_id"`
+	IsActive    bool    `json:"is_active"`
+	DaysActive  float64 `json:"days_active"`
+	Assignments int64   `json:"assignments"`
+	Share       float64 `json:"share"`
+	IdealShare  float64 `json:"ideal_share"`
+	LoadRatio   float64 `json:"load_ratio"`
+}
+
+type fairnessResponse struct {
+	TeamName         string                   `json:"team_name"`
+	From             string                   `json:"from"`
+	To               string                   `json:"to"`
+	Tolerance        float64                  `json:"tolerance"`
+	TotalAssignments int64                    `json:"total_assignments"`
+	Gini             float64                  `json:"gini"`
+	Members          []memberFairnessResponse `json:"members"`
+	Overloaded       []string                 `json:"overloaded"`
+	Underloaded      []string                 `json:"underloaded"`
+}
+
+// GetFairness: GET /stats/fairness?team_name=...&from=&to=&tolerance=.
+// По умолчанию окно — последние 30 дней, допуск — 0.25.
+func (h *StatsHandler) GetFairness(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	teamName := q.Get("team_name")
+	if teamName == "" {
+		respondError(w, r, errs.Invalid("team_name", "is required"))
+		return
+	}
+
+	to := time.Now().UTC()
+	if t, err := parseOptionalTime(q, "to"); err != nil {
+		respondError(w, r, err)
+		return
+	} else if t != nil {
+		to = *t
+	}
+	from := to.Add(-service.DefaultFairnessWindow)
+	if t, err := parseOptionalTime(q, "from"); err != nil {
+		respondError(w, r, err)
+		return
+	} else if t != nil {
+		from = *t
+	}
+	if !from.Before(to) {
+		respondError(w, r, errs.Invalid("from", "must be before to"))
+		return
+	}
+
+	tolerance := service.DefaultFairnessTolerance
+	if v := q.Get("tolerance"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t < 0 {
+			respondError(w, r, errs.Invalid("tolerance", "must be a non-negative number"))
+			return
+		}
+		tolerance = t
+	}
+
+	report, err := h.prSvc.GetFairnessReport(r.Context(), teamName, from, to, tolerance)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	members := make([]memberFairnessResponse, 0, len(report.Members))
+	for _, m := range report.Members {
+		members = append(members, memberFairnessResponse{
+			UserID:      m.UserID,
+			IsActive:    m.IsActive,
+			DaysActive:  m.DaysActive,
+			Assignments: m.Assignments,
+			Share:       m.Share,
+			IdealShare:  m.IdealShare,
+			LoadRatio:   m.LoadRatio,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, fairnessResponse{
+		TeamName:         report.TeamName,
+		From:             report.From.Format(time.RFC3339),
+		To:               report.To.Format(time.RFC3339),
+		Tolerance:        report.Tolerance,
+		TotalAssignments: report.TotalAssignments,
+		Gini:             report.Gini,
+		Members:          members,
+		Overloaded:       report.Overloaded,
+		Underloaded:      report.Underloaded,
+	})
+}
+
+type listPullRequestsResponse struct {
+	PullRequests []domain.PullRequest `json:"pull_requests"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+}
+
+// ListPullRequests: GET /pullRequests?status=&author_id=&reviewer_id=&team_name=
+// &created_from=&created_to=&merged_from=&merged_to=&sort=&order=&limit=&cursor=.
+func (h *PullRequestHandler) ListPullRequests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	f := repository.PRFilter{
+		AuthorID:   q.Get("author_id"),
+		ReviewerID: q.Get("reviewer_id"),
+		TeamName:   q.Get("team_name"),
+	}
+
+	var err error
+	if f.Page, err = parsePage(q); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if f.Order, err = parseOrder(q); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if f.Status, err = parsePRStatus(q); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	f.Sort, err = parsePRSort(q, repository.PRSortByID,
+		repository.PRSortByID, repository.PRSortByCreatedAt, repository.PRSortByMergedAt)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	for name, dst := range map[string]**time.Time{
+		"created_from": &f.CreatedFrom,
+		"created_to":   &f.CreatedTo,
+		"merged_from":  &f.MergedFrom,
+		"merged_to":    &f.MergedTo,
+	} {
+		if *dst, err = parseOptionalTime(q, name); err != nil {
+			respondError(w, r, err)
+			return
+		}
+	}
+
+	res, err := h.svc.ListPRs(r.Context(), f)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listPullRequestsResponse{
+		PullRequests: res.Items,
+		NextCursor:   res.NextCursor,
+	})
+}
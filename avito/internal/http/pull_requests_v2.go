@@ -0,0 +1,140 @@
+package http
+
+import (
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/service"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Ресурсные маршруты /v2 поверх тех же сервисов, что и v1. PR отдаётся
+// как есть, без обёртки {"pr": ...}; ошибки — в общем формате.
+
+type updatePullRequestRequest struct {
+	PullRequestName *string                   `json:"pull_request_name"`
+	Status          *domain.PullRequestStatus `json:"status"`
+}
+
+type reassignReviewerResponse struct {
+	PullRequest *domain.PullRequest `json:"pull_request"`
+	ReplacedBy  string              `json:"replaced_by"`
+}
+
+// pathParam — параметр пути {name}. chi берёт его из экранированного
+// пути, если тот отличается от разобранного, поэтому значение раскодируется.
+func pathParam(r *http.Request, name string) string {
+	v := chi.URLParam(r, name)
+	if s, err := url.PathUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
+
+// CreateV2: POST /v2/pull-requests.
+func (h *PullRequestHandler) CreateV2(w http.ResponseWriter, r *http.Request) {
+	var req createPullRequestRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if err := req.validate(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "pr_id", req.PullRequestID, "user_id", req.AuthorID)
+
+	pr, err := h.svc.Create(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v2/pull-requests/"+url.PathEscape(pr.ID))
+	respondJSON(w, http.StatusCreated, pr)
+}
+
+// GetV2: GET /v2/pull-requests/{id}.
+func (h *PullRequestHandler) GetV2(w http.ResponseWriter, r *http.Request) {
+	prID := pathParam(r, "id")
+	logging.Annotate(r.Context(), "pr_id", prID)
+
+	pr, err := h.svc.Get(r.Context(), prID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, pr)
+}
+
+// UpdateV2: PATCH /v2/pull-requests/{id}. Меняет pull_request_name
+// и/или переводит PR в MERGED; отсутствующие поля не трогаются.
+func (h *PullRequestHandler) UpdateV2(w http.ResponseWriter, r *http.Request) {
+	prID := pathParam(r, "id")
+
+	var req updatePullRequestRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	if req.PullRequestName != nil {
+		v.Name("pull_request_name", *req.PullRequestName)
+	}
+	if req.Status != nil && *req.Status != domain.PRStatusMerged {
+		v.Add("status", "only MERGED is supported")
+	}
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "pr_id", prID)
+
+	pr, err := h.svc.Update(r.Context(), prID, service.PullRequestPatch{
+		Name:   req.PullRequestName,
+		Status: req.Status,
+	})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, pr)
+}
+
+// AssignmentDecisionsV2: GET /v2/pull-requests/{id}/assignments.
+func (h *PullRequestHandler) AssignmentDecisionsV2(w http.ResponseWriter, r *http.Request) {
+	h.assignmentDecisions(w, r, pathParam(r, "id"))
+}
+
+// ReviewerActionV2: POST /v2/pull-requests/{id}/reviewers/{user}:reassign.
+// Действие разбирается здесь, а не шаблоном chi: user_id может содержать ':'.
+func (h *PullRequestHandler) ReviewerActionV2(w http.ResponseWriter, r *http.Request) {
+	prID := pathParam(r, "id")
+	userID, ok := strings.CutSuffix(pathParam(r, "action"), ":reassign")
+	if !ok || userID == "" {
+		notFound(w, r)
+		return
+	}
+
+	logging.Annotate(r.Context(), "pr_id", prID, "user_id", userID)
+
+	pr, replacedBy, err := h.svc.Reassign(r.Context(), prID, userID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, reassignReviewerResponse{
+		PullRequest: pr,
+		ReplacedBy:  replacedBy,
+	})
+}
+
+// UserReviewsV2: GET /v2/users/{id}/reviews?status=&since=&until=
+// &sort=created_at|merged_at&order=&limit=&cursor=.
+func (h *PullRequestHandler) UserReviewsV2(w http.ResponseWriter, r *http.Request) {
+	h.userReviews(w, r, pathParam(r, "id"))
+}
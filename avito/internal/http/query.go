@@ -0,0 +1,91 @@
+package http
+
+import (
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/repository"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Разбор общих query-параметров списочных эндпоинтов. Ошибки — VALIDATION_FAILED
+// с именем параметра, их отдаёт respondError.
+
+func parsePage(q url.Values) (repository.Page, error) {
+	p := repository.Page{Cursor: q.Get("cursor")}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return p, errs.Invalid("limit", "must be a positive integer")
+		}
+		p.Limit = n
+	}
+	return p, nil
+}
+
+func parseOrder(q url.Values) (repository.SortOrder, error) {
+	switch v := repository.SortOrder(q.Get("order")); v {
+	case "", repository.SortAsc:
+		return repository.SortAsc, nil
+	case repository.SortDesc:
+		return v, nil
+	default:
+		return "", errs.Invalid("order", "must be asc or desc")
+	}
+}
+
+func parseOptionalBool(q url.Values, name string) (*bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, errs.Invalid(name, "must be true or false")
+	}
+	return &b, nil
+}
+
+// parseOptionalTime принимает RFC3339 или дату вида 2006-01-02.
+func parseOptionalTime(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errs.Invalid(name, "must be RFC3339 timestamp or YYYY-MM-DD date")
+}
+
+func parsePRStatus(q url.Values) (domain.PullRequestStatus, error) {
+	switch status := domain.PullRequestStatus(q.Get("status")); status {
+	case "", domain.PRStatusOpen, domain.PRStatusMerged:
+		return status, nil
+	default:
+		return "", errs.Invalid("status", "must be OPEN or MERGED")
+	}
+}
+
+// parsePRSort разбирает sort из allowed; пустое значение даёт def.
+func parsePRSort(q url.Values, def repository.PRSortField, allowed ...repository.PRSortField) (repository.PRSortField, error) {
+	sort := repository.PRSortField(q.Get("sort"))
+	if sort == "" {
+		return def, nil
+	}
+	for _, a := range allowed {
+		if sort == a {
+			return sort, nil
+		}
+	}
+
+	names := make([]string, 0, len(allowed))
+	for _, a := range allowed {
+		names = append(names, string(a))
+	}
+	return "", errs.Invalid("sort", "must be one of: "+strings.Join(names, ", "))
+}
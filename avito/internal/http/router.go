@@ -1,116 +1,305 @@
 package http
 
 import (
-	"database/sql"
+	"cmp"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
-	pgrepo "avito/internal/repository/postgres"
+	"avito/internal/health"
+	"avito/internal/logging"
+	"avito/internal/metrics"
+	"avito/internal/ratelimit"
 	"avito/internal/service"
+	"avito/internal/tracing"
 )
 
-func NewRouter(db *sql.DB) http.Handler {
-	r := chi.NewRouter()
-
-	// middleware
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-
-	// репозитории Postgres
-	teamRepo := pgrepo.NewTeamRepo(db)
-	userRepo := pgrepo.NewUserRepo(db)
-	prRepo := pgrepo.NewPRRepo(db)
-
-	// сервисы
-	teamSvc := service.NewTeamService(teamRepo, userRepo, prRepo)
-	userSvc := service.NewUserService(userRepo)
-	prSvc := service.NewPullRequestService(prRepo, userRepo, teamRepo)
-
-	// инжектим prSvc обратно в teamSvc для BulkDeactivateTeam
-	teamSvc.SetPullRequestService(prSvc)
-
-	// хендлеры
-	teamHandler := NewTeamHandler(teamSvc)
-	userHandler := NewUserHandler(userSvc)
-	prHandler := NewPullRequestHandler(prSvc)
-	statsHandler := NewStatsHandler(prSvc)
-
-	// health-check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
-	})
-
-	// /team/*
-	r.Route("/team", func(r chi.Router) {
-		r.Post("/add", teamHandler.AddTeam)
-		r.Get("/get", teamHandler.GetTeam)
-		r.Post("/deactivateUsers", teamHandler.BulkDeactivate)
-	})
+// RouterOptions — зависимости и настройки роутера, собираемые в main.
+type RouterOptions struct {
+	Probe *health.Probe
+	// AuthEnabled требует API-ключ или JWT на всех маршрутах, кроме проб и метрик.
+	AuthEnabled bool
+	// RateLimits — лимиты по группам маршрутов; их разделяет gRPC-сервер.
+	RateLimits ratelimit.Groups
+	// MaxBodyBytes ограничивает тело запроса; 0 — DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// ValidateRequests отклоняет запросы, не соответствующие api/openapi.yml;
+	// ValidateResponses пишет в лог ответы, которые с ней расходятся.
+	ValidateRequests  bool
+	ValidateResponses bool
+	// EventsHeartbeat — интервал пингов в /events/stream; 0 —
+	// DefaultEventsHeartbeat.
+	EventsHeartbeat time.Duration
+	// WebSocket — настройки /ws/inbox; AuthEnabled берётся из опций роутера.
+	WebSocket InboxOptions
+}
 
-	// /users/*
-	r.Route("/users", func(r chi.Router) {
-		r.Post("/setIsActive", userHandler.SetIsActive)
-		r.Get("/getReview", prHandler.GetUserReviews)
-	})
+// DefaultMaxBodyBytes — предельный размер тела запроса по умолчанию.
+const DefaultMaxBodyBytes = 1 << 20
 
-	// /pullRequest/*
-	r.Route("/pullRequest", func(r chi.Router) {
-		r.Post("/create", prHandler.Create)
-		r.Post("/merge", prHandler.Merge)
-		r.Post("/reassign", prHandler.Reassign)
-	})
+func NewRouter(svcs *service.Services, opts RouterOptions) http.Handler {
+	r := chi.NewRouter()
+	useCommon(r, cmp.Or(opts.MaxBodyBytes, DefaultMaxBodyBytes))
 
-	// эндпоинт статистики
-	r.Get("/stats", statsHandler.GetStats)
+	routes := apiRoutes{
+		teams:    NewTeamHandler(svcs.Teams),
+		users:    NewUserHandler(svcs.Users),
+		prs:      NewPullRequestHandler(svcs.PullRequests),
+		stats:    NewStatsHandler(svcs.PullRequests),
+		auth:     NewAuthHandler(svcs.Auth),
+		orgs:     NewOrgHandler(svcs.Orgs),
+		graphql:  NewGraphQLHandler(svcs, opts.RateLimits),
+		tenant:   resolveTenant(svcs.Orgs),
+		validate: openapiValidation(opts.ValidateRequests, opts.ValidateResponses, nil),
+		limit: func(group string) func(http.Handler) http.Handler {
+			l := opts.RateLimits[group]
+			if l == nil {
+				return nil
+			}
+			if group == ratelimit.GroupIP {
+				return rateLimit(group, l, ipKey)
+			}
+			return rateLimit(group, l, clientKey)
+		},
+	}
+	if svcs.Events != nil {
+		routes.events = NewEventHandler(svcs.Events, opts.EventsHeartbeat)
+	}
+	if svcs.Inbox != nil {
+		opts.WebSocket.AuthEnabled = opts.AuthEnabled
+		routes.inbox = NewInboxHandler(svcs.Inbox, svcs.Auth, svcs.Orgs, opts.WebSocket)
+	}
+	if opts.AuthEnabled {
+		routes.authenticate = authenticate(svcs.Auth)
+	}
 
+	mountPublic(r, opts.Probe)
+	routes.mount(r)
 	return r
 }
 
+// NewRouterForTest собирает те же маршруты без аутентификации, тенантов
+// и лимитов поверх готовых сервисов. Каждый запрос и ответ сверяется со
+// спецификацией: расхождения ответов получает onDrift, чтобы тест мог на
+// них упасть; nil пишет их в лог прогона.
 func NewRouterForTest(
 	teamSvc *service.TeamService,
 	userSvc *service.UserService,
 	prSvc *service.PullRequestService,
+	onDrift DriftFunc,
 ) http.Handler {
 	r := chi.NewRouter()
+	useCommon(r, DefaultMaxBodyBytes)
+
+	mountPublic(r, health.NewProbe())
+	apiRoutes{
+		teams: NewTeamHandler(teamSvc),
+		users: NewUserHandler(userSvc),
+		prs:   NewPullRequestHandler(prSvc),
+		stats: NewStatsHandler(prSvc),
+		graphql: NewGraphQLHandler(&service.Services{
+			Teams:        teamSvc,
+			Users:        userSvc,
+			PullRequests: prSvc,
+		}, nil),
+		validate: openapiValidation(true, true, onDrift),
+	}.mount(r)
+	return r
+}
 
+// useCommon подключает middleware, общие для всех маршрутов.
+func useCommon(r chi.Router, maxBodyBytes int64) {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(tracing.Middleware)
+	r.Use(logging.Middleware)
+	// метрики снаружи Recoverer: паника учитывается как ответ 500
+	r.Use(metrics.Middleware)
 	r.Use(middleware.Recoverer)
+	r.Use(maxBodySize(maxBodyBytes))
+	r.NotFound(notFound)
+	r.MethodNotAllowed(methodNotAllowed)
+}
 
-	teamHandler := NewTeamHandler(teamSvc)
-	userHandler := NewUserHandler(userSvc)
-	prHandler := NewPullRequestHandler(prSvc)
-	statsHandler := NewStatsHandler(prSvc)
-
+// mountPublic объявляет маршруты без аутентификации: пробы, метрики
+// и документацию.
+func mountPublic(r chi.Router, probe *health.Probe) {
+	// health-check
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
 
-	r.Route("/team", func(r chi.Router) {
-		r.Post("/add", teamHandler.AddTeam)
-		r.Get("/get", teamHandler.GetTeam)
-		r.Post("/deactivateUsers", teamHandler.BulkDeactivate)
-	})
+	// пробы: liveness без зависимостей, readiness с проверками
+	r.Get("/livez", probe.Livez)
+	r.Get("/readyz", probe.Readyz)
 
-	r.Route("/users", func(r chi.Router) {
-		r.Post("/setIsActive", userHandler.SetIsActive)
-		r.Get("/getReview", prHandler.GetUserReviews)
-	})
+	// метрики Prometheus
+	r.Handle("/metrics", metrics.Handler())
 
-	r.Route("/pullRequest", func(r chi.Router) {
-		r.Post("/create", prHandler.Create)
-		r.Post("/merge", prHandler.Merge)
-		r.Post("/reassign", prHandler.Reassign)
-	})
+	// спецификация API и Swagger UI
+	r.Get("/openapi.yml", serveSpec)
+	r.Get("/docs", serveDocs)
+}
 
-	r.Get("/stats", statsHandler.GetStats)
+// apiRoutes — хендлеры и middleware маршрутов API. NewRouter и
+// NewRouterForTest различаются только ими, сами маршруты объявлены
+// один раз в mount. nil-middleware пропускается; без auth, orgs, graphql,
+// events и inbox маршруты /auth/*, /orgs, /graphql, /events/stream
+// и /ws/inbox не объявляются.
+type apiRoutes struct {
+	teams   *TeamHandler
+	users   *UserHandler
+	prs     *PullRequestHandler
+	stats   *StatsHandler
+	auth    *AuthHandler
+	orgs    *OrgHandler
+	graphql *GraphQLHandler
+	events  *EventHandler
+	inbox   *InboxHandler
 
-	return r
+	authenticate func(http.Handler) http.Handler
+	tenant       func(http.Handler) http.Handler
+	validate     func(http.Handler) http.Handler
+	// limit возвращает лимит частоты группы маршрутов (ratelimit.Group*).
+	limit func(group string) func(http.Handler) http.Handler
+}
+
+func (a apiRoutes) mount(r chi.Router) {
+	// у каждой группы маршрутов своя корзина на клиента, общая для v1 и v2;
+	// ipLimit стоит до аутентификации и считает и запросы с неверным ключом
+	var ipLimit, defaultLimit, teamsLimit, prLimit, statsLimit func(http.Handler) http.Handler
+	if a.limit != nil {
+		ipLimit = a.limit(ratelimit.GroupIP)
+		defaultLimit = a.limit(ratelimit.GroupDefault)
+		teamsLimit = a.limit(ratelimit.GroupTeams)
+		prLimit = a.limit(ratelimit.GroupPullRequests)
+		statsLimit = a.limit(ratelimit.GroupStats)
+	}
+
+	// WebSocket-инбокс аутентифицирует и выбирает организацию сам:
+	// браузер не может передать ключ заголовком рукопожатия
+	if a.inbox != nil {
+		r.Group(func(r chi.Router) {
+			use(r, ipLimit, a.validate, defaultLimit)
+			r.Get("/ws/inbox", a.inbox.Connect)
+		})
+	}
+
+	// всё остальное — за аутентификацией
+	r.Group(func(r chi.Router) {
+		use(r, ipLimit, a.authenticate, a.validate)
+
+		// маршруты вне тенанта запроса: организации (права проверяет
+		// OrgService) и настройки процесса
+		r.Group(func(r chi.Router) {
+			use(r, defaultLimit)
+
+			if a.orgs != nil {
+				r.Get("/orgs", a.orgs.ListOrgs)
+				r.Post("/orgs", a.orgs.CreateOrg)
+				r.Get("/orgs/get", a.orgs.GetOrg)
+			}
+
+			// уровень логирования процесса — общий для всех организаций
+			r.With(requirePlatformAdmin).Get("/admin/logLevel", getLogLevel)
+			r.With(requirePlatformAdmin).Put("/admin/logLevel", setLogLevel)
+		})
+
+		// остальные маршруты работают с данными одной организации
+		r.Group(func(r chi.Router) {
+			use(r, a.tenant)
+
+			r.Group(func(r chi.Router) {
+				use(r, defaultLimit)
+
+				// API-ключи; права проверяет AuthService
+				if a.auth != nil {
+					r.Get("/auth/whoami", a.auth.WhoAmI)
+					r.Get("/auth/keys", a.auth.ListKeys)
+					r.Post("/auth/keys", a.auth.CreateKey)
+					r.Post("/auth/keys/revoke", a.auth.RevokeKey)
+				}
+
+				// списки с курсорной пагинацией; GET /users объявлен внутри /users/*
+				r.Get("/teams", a.teams.ListTeams)
+				r.Get("/pullRequests", a.prs.ListPullRequests)
+
+				// /users/*
+				r.Route("/users", func(r chi.Router) {
+					r.Get("/", a.users.ListUsers)
+					r.Post("/setIsActive", a.users.SetIsActive)
+					r.Get("/getReview", a.prs.GetUserReviews)
+				})
+
+				r.Get("/v2/users/{id}/reviews", a.prs.UserReviewsV2)
+
+				// /graphql списывает ещё и корневые поля из лимитов их групп
+				// (NewGraphQLHandler), иначе мутации и статистика обходили бы
+				// prLimit и statsLimit
+				if a.graphql != nil {
+					r.Post("/graphql", a.graphql.Query)
+				}
+				if a.events != nil {
+					r.Get("/events/stream", a.events.Stream)
+				}
+			})
+
+			// /team/*
+			r.Route("/team", func(r chi.Router) {
+				use(r, teamsLimit)
+
+				r.Post("/add", a.teams.AddTeam)
+				r.Get("/get", a.teams.GetTeam)
+				r.Post("/deactivateUsers", a.teams.BulkDeactivate)
+				r.Post("/addMembers", a.teams.AddMembers)
+				r.Post("/removeMembers", a.teams.RemoveMembers)
+				r.Post("/moveMember", a.teams.MoveMember)
+				r.Post("/rename", a.teams.RenameTeam)
+				r.Post("/delete", a.teams.DeleteTeam)
+			})
+
+			// /pullRequest/*
+			r.Route("/pullRequest", func(r chi.Router) {
+				use(r, prLimit)
+
+				r.Post("/create", a.prs.Create)
+				r.Post("/merge", a.prs.Merge)
+				r.Post("/reassign", a.prs.Reassign)
+				r.Get("/assignments", a.prs.GetAssignmentDecisions)
+			})
+
+			// /v2/pull-requests — ресурсный вариант /pullRequest/*
+			r.Route("/v2/pull-requests", func(r chi.Router) {
+				use(r, prLimit)
+
+				r.Get("/", a.prs.ListPullRequests)
+				r.Post("/", a.prs.CreateV2)
+				r.Get("/{id}", a.prs.GetV2)
+				r.Patch("/{id}", a.prs.UpdateV2)
+				r.Get("/{id}/assignments", a.prs.AssignmentDecisionsV2)
+				r.Post("/{id}/reviewers/{action}", a.prs.ReviewerActionV2)
+			})
+
+			// эндпоинт статистики
+			r.Group(func(r chi.Router) {
+				use(r, statsLimit)
+
+				r.Get("/stats", a.stats.GetStats)
+				r.Get("/stats/latency", a.stats.GetMergeLatency)
+				r.Get("/stats/fairness", a.stats.GetFairness)
+			})
+		})
+	})
+}
+
+// use подключает заданные middleware, пропуская nil.
+func use(r chi.Router, mws ...func(http.Handler) http.Handler) {
+	for _, mw := range mws {
+		if mw != nil {
+			r.Use(mw)
+		}
+	}
 }
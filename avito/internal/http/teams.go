@@ -3,6 +3,8 @@ package http
 import (
 	"avito/internal/domain"
 	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
 	"avito/internal/service"
 	"encoding/json"
 	"net/http"
@@ -28,18 +30,19 @@ type bulkDeactivateResponse struct {
 
 func (h *TeamHandler) BulkDeactivate(w http.ResponseWriter, r *http.Request) {
 	var req bulkDeactivateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 	if req.TeamName == "" {
-		http.Error(w, "team_name is required", http.StatusBadRequest)
+		respondError(w, r, errs.Invalid("team_name", "is required"))
 		return
 	}
 
+	logging.Annotate(r.Context(), "team_name", req.TeamName)
+
 	res, err := h.svc.BulkDeactivateTeam(r.Context(), req.TeamName)
 	if err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
@@ -54,20 +57,22 @@ func (h *TeamHandler) BulkDeactivate(w http.ResponseWriter, r *http.Request) {
 // POST /team/add
 func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	var team domain.Team
-	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSON(w, r, &team) {
+		return
+	}
+	var v errs.Validator
+	v.Name("team_name", team.TeamName)
+	v.Members("members", team.Members)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	created, err := h.svc.CreateTeam(team)
+	logging.Annotate(r.Context(), "team_name", team.TeamName)
+
+	created, err := h.svc.CreateTeam(r.Context(), team)
 	if err != nil {
-		if appErr, ok := err.(*errs.AppError); ok {
-			if appErr.Code == errs.CodeTeamExists {
-				WriteAppError(w, appErr, http.StatusBadRequest)
-				return
-			}
-		}
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
@@ -82,22 +87,252 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	teamName := r.URL.Query().Get("team_name")
 	if teamName == "" {
-		http.Error(w, "team_name is required", http.StatusBadRequest)
+		respondError(w, r, errs.Invalid("team_name", "is required"))
 		return
 	}
 
-	team, err := h.svc.GetTeam(teamName)
+	logging.Annotate(r.Context(), "team_name", teamName)
+
+	team, err := h.svc.GetTeam(r.Context(), teamName)
 	if err != nil {
-		if appErr, ok := err.(*errs.AppError); ok {
-			if appErr.Code == errs.CodeNotFound {
-				WriteAppError(w, appErr, http.StatusNotFound)
-				return
-			}
-		}
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(team)
 }
+
+type addMembersRequest struct {
+	TeamName string              `json:"team_name"`
+	Members  []domain.TeamMember `json:"members"`
+}
+
+type removeMembersRequest struct {
+	TeamName        string   `json:"team_name"`
+	UserIDs         []string `json:"user_ids"`
+	ReassignReviews bool     `json:"reassign_reviews"`
+}
+
+type moveMemberRequest struct {
+	UserID          string `json:"user_id"`
+	ToTeamName      string `json:"to_team_name"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+type membershipChangeResponse struct {
+	TeamName            string `json:"team_name"`
+	ChangedUsers        int64  `json:"changed_users"`
+	ReassignedReviewers int64  `json:"reassigned_reviewers"`
+	UnresolvedReviews   int64  `json:"unresolved_reviews"`
+}
+
+func newMembershipChangeResponse(res *service.MembershipChangeResult) membershipChangeResponse {
+	return membershipChangeResponse{
+		TeamName:            res.TeamName,
+		ChangedUsers:        res.ChangedUsers,
+		ReassignedReviewers: res.ReassignedReviewers,
+		UnresolvedReviews:   res.UnresolvedReviews,
+	}
+}
+
+// POST /team/addMembers
+func (h *TeamHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	var req addMembersRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	v.Required("team_name", req.TeamName)
+	if len(req.Members) == 0 {
+		v.Add("members", "must not be empty")
+	}
+	v.Members("members", req.Members)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "team_name", req.TeamName)
+
+	team, err := h.svc.AddMembers(r.Context(), req.TeamName, req.Members)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team": team,
+	})
+}
+
+// POST /team/removeMembers
+func (h *TeamHandler) RemoveMembers(w http.ResponseWriter, r *http.Request) {
+	var req removeMembersRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	v.Required("team_name", req.TeamName)
+	if len(req.UserIDs) == 0 {
+		v.Add("user_ids", "must not be empty")
+	}
+	v.UserIDs("user_ids", req.UserIDs)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "team_name", req.TeamName)
+
+	res, err := h.svc.RemoveMembers(r.Context(), req.TeamName, req.UserIDs, req.ReassignReviews)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newMembershipChangeResponse(res))
+}
+
+// POST /team/moveMember
+func (h *TeamHandler) MoveMember(w http.ResponseWriter, r *http.Request) {
+	var req moveMemberRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	v.Required("user_id", req.UserID)
+	v.Required("to_team_name", req.ToTeamName)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "user_id", req.UserID, "team_name", req.ToTeamName)
+
+	res, err := h.svc.MoveMember(r.Context(), req.UserID, req.ToTeamName, req.ReassignReviews)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, newMembershipChangeResponse(res))
+}
+
+type renameTeamRequest struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+type deleteTeamRequest struct {
+	TeamName      string `json:"team_name"`
+	MoveMembersTo string `json:"move_members_to"`
+}
+
+type deleteTeamResponse struct {
+	TeamName      string `json:"team_name"`
+	MovedUsers    int64  `json:"moved_users"`
+	MoveMembersTo string `json:"move_members_to,omitempty"`
+	RevokedKeys   int64  `json:"revoked_keys"`
+}
+
+// POST /team/rename
+func (h *TeamHandler) RenameTeam(w http.ResponseWriter, r *http.Request) {
+	var req renameTeamRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var v errs.Validator
+	v.Required("team_name", req.TeamName)
+	v.Name("new_team_name", req.NewTeamName)
+	if err := v.Err(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	logging.Annotate(r.Context(), "team_name", req.TeamName)
+
+	team, err := h.svc.RenameTeam(r.Context(), req.TeamName, req.NewTeamName)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team": team,
+	})
+}
+
+// POST /team/delete
+func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	var req deleteTeamRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.TeamName == "" {
+		respondError(w, r, errs.Invalid("team_name", "is required"))
+		return
+	}
+
+	logging.Annotate(r.Context(), "team_name", req.TeamName)
+
+	res, err := h.svc.DeleteTeam(r.Context(), req.TeamName, req.MoveMembersTo)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, deleteTeamResponse{
+		TeamName:      res.TeamName,
+		MovedUsers:    res.MovedUsers,
+		MoveMembersTo: res.MovedToTeam,
+		RevokedKeys:   res.RevokedKeys,
+	})
+}
+
+type teamSummaryResponse struct {
+	TeamName           string `json:"team_name"`
+	MembersCount       int64  `json:"members_count"`
+	ActiveMembersCount int64  `json:"active_members_count"`
+}
+
+type listTeamsResponse struct {
+	Teams      []teamSummaryResponse `json:"teams"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// GET /teams?order=&limit=&cursor=
+func (h *TeamHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, err := parsePage(q)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	order, err := parseOrder(q)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	res, err := h.svc.ListTeams(r.Context(), repository.TeamFilter{Order: order, Page: page})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	out := make([]teamSummaryResponse, 0, len(res.Items))
+	for _, t := range res.Items {
+		out = append(out, teamSummaryResponse{
+			TeamName:           t.TeamName,
+			MembersCount:       t.Members,
+			ActiveMembersCount: t.ActiveMembers,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, listTeamsResponse{
+		Teams:      out,
+		NextCursor: res.NextCursor,
+	})
+}
@@ -1,7 +1,10 @@
 package http
 
 import (
+	"avito/internal/domain"
 	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
 	"avito/internal/service"
 	"encoding/json"
 	"net/http"
@@ -23,24 +26,19 @@ type setIsActiveRequest struct {
 // POST /users/setIsActive
 func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	var req setIsActiveRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 	if req.UserID == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+		respondError(w, r, errs.Invalid("user_id", "is required"))
 		return
 	}
 
-	user, err := h.svc.SetIsActive(req.UserID, req.IsActive)
+	logging.Annotate(r.Context(), "user_id", req.UserID)
+
+	user, err := h.svc.SetIsActive(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
-		if appErr, ok := err.(*errs.AppError); ok {
-			if appErr.Code == errs.CodeNotFound {
-				WriteAppError(w, appErr, http.StatusNotFound)
-				return
-			}
-		}
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		respondError(w, r, err)
 		return
 	}
 
@@ -49,3 +47,56 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 		"user": user,
 	})
 }
+
+type listUsersResponse struct {
+	Users      []domain.User `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// GET /users?team_name=&is_active=&sort=&order=&limit=&cursor=
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, err := parsePage(q)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	order, err := parseOrder(q)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	isActive, err := parseOptionalBool(q, "is_active")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	sort := repository.UserSortField(q.Get("sort"))
+	switch sort {
+	case "":
+		sort = repository.UserSortByID
+	case repository.UserSortByID, repository.UserSortByUsername:
+	default:
+		respondError(w, r, errs.Invalid("sort", "must be user_id or username"))
+		return
+	}
+
+	res, err := h.svc.ListUsers(r.Context(), repository.UserFilter{
+		TeamName: q.Get("team_name"),
+		IsActive: isActive,
+		Sort:     sort,
+		Order:    order,
+		Page:     page,
+	})
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listUsersResponse{
+		Users:      res.Items,
+		NextCursor: res.NextCursor,
+	})
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level общий для всех логгеров сервиса и меняется на лету через SetLevel.
+var level = new(slog.LevelVar)
+
+// Setup делает JSON-логгер в stdout логгером по умолчанию, в том числе
+// для стандартного пакета log. Пустое levelName означает info.
+func Setup(levelName string) error {
+	if err := SetLevel(levelName); err != nil {
+		return err
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Level возвращает текущий уровень логирования.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel меняет уровень логирования: debug, info, warn или error.
+func SetLevel(name string) error {
+	if name == "" {
+		name = "info"
+	}
+
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+		return fmt.Errorf("unknown log level %q", name)
+	}
+	level.Set(l)
+	return nil
+}
+
+type loggerKey struct{}
+
+// FromContext возвращает логгер запроса, а вне запроса — логгер по умолчанию.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// WithLogger кладёт логгер в контекст.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// With возвращает контекст, логгер которого дополнен атрибутами args;
+// ими удобно помечать записи идентификаторами PR, команды и пользователя.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
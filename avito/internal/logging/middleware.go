@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestAttrs — атрибуты итоговой записи о запросе, которые добавляют
+// хендлеры после разбора тела (идентификаторы PR, команды, пользователя).
+type requestAttrs struct {
+	mu    sync.Mutex
+	attrs []any
+}
+
+type requestAttrsKey struct{}
+
+// Annotate добавляет атрибуты в итоговую запись о текущем запросе
+// (HTTP или gRPC). Вне запроса вызов ничего не делает.
+func Annotate(ctx context.Context, args ...any) {
+	ra, ok := ctx.Value(requestAttrsKey{}).(*requestAttrs)
+	if !ok {
+		return
+	}
+	ra.mu.Lock()
+	ra.attrs = append(ra.attrs, args...)
+	ra.mu.Unlock()
+}
+
+// WithRequestAttrs возвращает контекст, в который Annotate собирает
+// атрибуты итоговой записи о запросе, и функцию, отдающую собранное.
+func WithRequestAttrs(ctx context.Context) (context.Context, func() []any) {
+	ra := &requestAttrs{}
+	return context.WithValue(ctx, requestAttrsKey{}, ra), func() []any {
+		ra.mu.Lock()
+		defer ra.mu.Unlock()
+		return append([]any(nil), ra.attrs...)
+	}
+}
+
+// Middleware кладёт в контекст логгер запроса с request_id и trace_id
+// и по завершении пишет одну запись с маршрутом, статусом и длительностью.
+// Должен стоять после middleware.RequestID и трассировки.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := r.Context()
+
+		logger := slog.Default().With(
+			"request_id", middleware.GetReqID(ctx),
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			logger = logger.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+		}
+
+		ctx, annotations := WithRequestAttrs(WithLogger(ctx, logger))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		args := []any{
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"remote_addr", r.RemoteAddr,
+		}
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			args = append(args, "route", rctx.RoutePattern())
+		}
+		args = append(args, annotations()...)
+
+		lvl := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			lvl = slog.LevelError
+		case status >= http.StatusBadRequest:
+			lvl = slog.LevelWarn
+		}
+		logger.Log(ctx, lvl, "http request", args...)
+	})
+}
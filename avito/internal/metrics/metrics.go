@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_reviewer"
+
+// Registry содержит все метрики сервиса; отдаётся на /metrics.
+var Registry = prometheus.NewRegistry()
+
+// Доменные счётчики; инкрементируются сервисным слоем.
+var (
+	PRsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_created_total",
+		Help:      "Number of created pull requests.",
+	})
+	PRsMerged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_merged_total",
+		Help:      "Number of merged pull requests (repeated merges are not counted).",
+	})
+	Reassignments = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewer_reassignments_total",
+		Help:      "Number of successful reviewer reassignments.",
+	})
+	NoCandidate = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Number of reassignments that failed with NO_CANDIDATE.",
+	})
+	BulkDeactivations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bulk_deactivations_total",
+		Help:      "Number of bulk team deactivations.",
+	})
+	BulkDeactivatedUsers = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bulk_deactivated_users_total",
+		Help:      "Number of users deactivated by bulk team deactivations.",
+	})
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_rate_limited_total",
+		Help:      "Number of requests rejected by the rate limiter by route group.",
+	}, []string{"group"})
+	GRPCRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_rate_limited_total",
+		Help:      "Number of gRPC calls rejected by the rate limiter by group.",
+	}, []string{"group"})
+	EventSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_subscribers",
+		Help:      "Current number of event subscribers (SSE streams and inbox WebSockets) in this process.",
+	})
+	EventSubscribersDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_subscribers_dropped_total",
+		Help:      "Number of event subscribers dropped because their buffer was full.",
+	})
+	WebSocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_connections",
+		Help:      "Current number of inbox WebSocket connections in this process.",
+	})
+	WebSocketRejected = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "websocket_rejected_total",
+		Help:      "Number of inbox WebSocket connections rejected by the per-process connection limit.",
+	})
+)
+
+// Gauges текущего состояния; обновляются GaugeRefresher.
+var (
+	OpenPRs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "open_pull_requests",
+		Help:      "Current number of open pull requests by organization and author team.",
+	}, []string{"org", "team"})
+	OpenReviews = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "open_reviews",
+		Help:      "Current number of open review assignments by organization and reviewer team.",
+	}, []string{"org", "team"})
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		PRsCreated,
+		PRsMerged,
+		Reassignments,
+		NoCandidate,
+		BulkDeactivations,
+		BulkDeactivatedUsers,
+		RateLimited,
+		GRPCRateLimited,
+		EventSubscribers,
+		EventSubscribersDropped,
+		WebSocketConnections,
+		WebSocketRejected,
+		OpenPRs,
+		OpenReviews,
+		httpRequests,
+		httpDuration,
+	)
+}
+
+// RegisterDB добавляет метрики пула соединений из sql.DB.Stats().
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "app"))
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware считает запросы и их длительность. В качестве route берётся
+// шаблон маршрута chi, чтобы не плодить метки по значениям параметров.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{
+			"route":  route,
+			"method": r.Method,
+			"status": strconv.Itoa(status),
+		}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"avito/internal/repository"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// OpenCountsSource отдаёт текущее число открытых PR и ревью по командам
+// всех организаций.
+type OpenCountsSource interface {
+	GetOpenCountsByTeam(ctx context.Context) (map[repository.OrgTeam]repository.OpenCounts, error)
+}
+
+// RunGaugeRefresher периодически обновляет OpenPRs и OpenReviews, пока ctx
+// не отменён. Блокирует вызывающего, поэтому запускается в отдельной горутине.
+// Если beat не nil, он получает результат каждого обновления.
+func RunGaugeRefresher(ctx context.Context, src OpenCountsSource, interval time.Duration, beat func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := refreshGauges(ctx, src)
+		if beat != nil && ctx.Err() == nil {
+			beat(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func refreshGauges(ctx context.Context, src OpenCountsSource) error {
+	counts, err := src.GetOpenCountsByTeam(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "refresh open counts gauges", "error", err)
+		}
+		return err
+	}
+
+	// команды могли быть переименованы или удалены — сбрасываем старые метки
+	OpenPRs.Reset()
+	OpenReviews.Reset()
+	for key, c := range counts {
+		OpenPRs.WithLabelValues(key.OrgID, key.TeamName).Set(float64(c.PullRequests))
+		OpenReviews.WithLabelValues(key.OrgID, key.TeamName).Set(float64(c.Reviews))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package ratelimit
+
+// Группы с отдельными лимитами. Корзины группы общие для HTTP и gRPC:
+// клиент не получает двойной лимит, переходя с одного транспорта на другой.
+const (
+	// GroupIP ограничивает IP-адрес до аутентификации, так что запросы без
+	// ключа или с неверным ключом тоже расходуют лимит.
+	GroupIP           = "ip"
+	GroupDefault      = "default"
+	GroupPullRequests = "pull_requests"
+	GroupTeams        = "teams"
+	GroupStats        = "stats"
+)
+
+// Groups — лимитеры по группам (Group*); группа без лимитера не ограничивается.
+type Groups map[string]*Limiter
+
+// NewGroups создаёт по лимитеру на каждую группу limits.
+func NewGroups(limits map[string]Limit) Groups {
+	g := make(Groups, len(limits))
+	for name, l := range limits {
+		g[name] = New(l)
+	}
+	return g
+}
@@ -0,0 +1,117 @@
+// Package ratelimit реализует token bucket на каждого клиента.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleTTL — через сколько простоя корзина клиента удаляется. При разумных
+// лимитах к этому моменту она снова полная, так что удаление ничего не меняет.
+const idleTTL = 10 * time.Minute
+
+// Limit — скорость пополнения (запросов в секунду) и ёмкость корзины.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// Decision — результат проверки, из которого строятся заголовки RateLimit-*.
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset — через сколько корзина наполнится полностью.
+	Reset time.Duration
+	// RetryAfter — через сколько появится следующий токен; ноль, если
+	// запрос пропущен.
+	RetryAfter time.Duration
+}
+
+// Stricter сводит решения двух лимитов, через которые прошёл один запрос,
+// в одно для заголовков: остаток меньший, время до полной корзины большее,
+// а Limit — того лимита, что ближе к исчерпанию. Запрос пропущен, только
+// если его пропустили оба.
+func (d Decision) Stricter(o Decision) Decision {
+	res := d
+	if o.Remaining < d.Remaining {
+		res.Limit, res.Remaining = o.Limit, o.Remaining
+	}
+	res.Reset = max(d.Reset, o.Reset)
+	res.RetryAfter = max(d.RetryAfter, o.RetryAfter)
+	res.Allowed = d.Allowed && o.Allowed
+	return res
+}
+
+type bucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+// Limiter хранит корзины клиентов одной группы маршрутов.
+type Limiter struct {
+	limit Limit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(limit Limit) *Limiter {
+	return &Limiter{
+		limit:     limit,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow списывает токен из корзины клиента key, если он есть.
+func (l *Limiter) Allow(key string) Decision {
+	now := l.now()
+	burst := float64(l.limit.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, seen: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.seen).Seconds()*l.limit.RPS)
+	b.seen = now
+
+	d := Decision{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.duration(burst - b.tokens)
+	return d
+}
+
+// duration — за сколько накопится tokens токенов.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.limit.RPS * float64(time.Second))
+}
+
+// sweep раз в idleTTL удаляет корзины клиентов, которые давно не приходили.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.seen) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+	"database/sql"
+)
+
+type APIKeyRepo struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+// TeamName и TeamID ключа member — текущая команда его пользователя.
+const apiKeySelect = `
+SELECT k.id, k.org_id, k.name, k.prefix, k.role,
+       COALESCE(k.user_id, ''),
+       COALESCE(kt.team_name, ut.team_name, ''),
+       COALESCE(kt.id, ut.id, 0),
+       k.created_at, k.last_used_at, k.revoked_at
+FROM api_keys k
+LEFT JOIN teams kt ON kt.id = k.team_id
+LEFT JOIN users u ON u.org_id = k.org_id AND u.user_id = k.user_id
+LEFT JOIN teams ut ON ut.id = u.team_id`
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key domain.APIKey, hash []byte) (*domain.APIKey, error) {
+	var userID, teamName sql.NullString
+	if key.UserID != "" {
+		userID = sql.NullString{String: key.UserID, Valid: true}
+	}
+	if key.Role == domain.RoleTeamLead {
+		teamName = sql.NullString{String: key.TeamName, Valid: true}
+	}
+
+	var id int64
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO api_keys (org_id, name, key_hash, prefix, role, user_id, team_id)
+         VALUES ($1, $2, $3, $4, $5, $6, (SELECT id FROM teams WHERE org_id = $1 AND team_name = $7))
+         RETURNING id`,
+		orgID(ctx), key.Name, hash, key.Prefix, string(key.Role), userID, teamName,
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, repository.ErrAlreadyExists
+		}
+		return nil, err
+	}
+
+	return r.getAPIKey(ctx, `k.id = $1`, id)
+}
+
+// GetAPIKeyByHash ищет ключ во всех организациях: именно ключ определяет,
+// к какой организации относится запрос.
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash []byte) (*domain.APIKey, error) {
+	return r.getAPIKey(ctx, `k.key_hash = $1`, hash)
+}
+
+func (r *APIKeyRepo) getAPIKey(ctx context.Context, where string, arg any) (*domain.APIKey, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, apiKeySelect+` WHERE `+where, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &keys[0], nil
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		apiKeySelect+` WHERE k.org_id = $1 ORDER BY k.id`,
+		orgID(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows)
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE api_keys
+         SET revoked_at = COALESCE(revoked_at, now())
+         WHERE org_id = $1 AND id = $2`,
+		orgID(ctx), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return r.getAPIKey(ctx, `k.id = $1`, id)
+}
+
+// RevokeTeamKeys считает только ключи, отозванные этим вызовом: уже
+// отозванные лишь отвязываются от команды.
+func (r *APIKeyRepo) RevokeTeamKeys(ctx context.Context, teamName string) (int64, error) {
+	var n int64
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`WITH k AS (
+             SELECT id, revoked_at IS NULL AS live
+             FROM api_keys
+             WHERE org_id = $1
+               AND team_id = (SELECT id FROM teams WHERE org_id = $1 AND team_name = $2)
+             FOR UPDATE
+         ), upd AS (
+             UPDATE api_keys a
+             SET revoked_at = COALESCE(a.revoked_at, now()),
+                 team_id = NULL
+             FROM k
+             WHERE a.id = k.id
+             RETURNING k.live
+         )
+         SELECT count(*) FILTER (WHERE live) FROM upd`,
+		orgID(ctx), teamName,
+	).Scan(&n)
+	return n, err
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE api_keys
+         SET last_used_at = now()
+         WHERE id = $1
+           AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`,
+		id,
+	)
+	return err
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]domain.APIKey, error) {
+	keys := make([]domain.APIKey, 0)
+	for rows.Next() {
+		var (
+			k    domain.APIKey
+			role string
+		)
+		if err := rows.Scan(
+			&k.ID, &k.OrgID, &k.Name, &k.Prefix, &role, &k.UserID, &k.TeamName, &k.TeamID,
+			&k.CreatedAt, &k.LastUsedAt, &k.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		k.Role = domain.Role(role)
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
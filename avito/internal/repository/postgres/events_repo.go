@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type EventRepo struct {
+	db *sql.DB
+}
+
+func NewEventRepo(db *sql.DB) *EventRepo {
+	return &EventRepo{db: db}
+}
+
+const eventColumns = `id, org_id, type, pull_request, COALESCE(team_name, ''),
+       COALESCE(old_reviewer_id, ''), COALESCE(new_reviewer_id, ''), COALESCE(acted_by, ''), created_at`
+
+// eventsLockClass — первый ключ advisory-блокировки журнала событий
+// организации; второй — хеш её id.
+const eventsLockClass = 4910
+
+// AppendEvent берёт команду автора из базы: при merge и переназначении
+// сервис её не загружает.
+//
+// id событий организации выдаются в порядке фиксации транзакций: до
+// вставки транзакция берёт advisory-блокировку журнала организации
+// и держит её до конца. Иначе событие с меньшим id могло бы стать видимым
+// позже большего, и клиент, продолжающий поток с Last-Event-ID, потерял бы его.
+func (r *EventRepo) AppendEvent(ctx context.Context, e domain.Event) (*domain.Event, error) {
+	pr, err := json.Marshal(e.PullRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	created := e
+	created.OrgID = orgID(ctx)
+	err = inTx(ctx, r.db, func(q querier) error {
+		_, err := q.ExecContext(ctx,
+			`SELECT pg_advisory_xact_lock($1, hashtext($2))`, eventsLockClass, created.OrgID)
+		if err != nil {
+			return err
+		}
+		return appendEvent(ctx, q, &created, pr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func appendEvent(ctx context.Context, q querier, e *domain.Event, pr []byte) error {
+	return q.QueryRowContext(ctx,
+		`INSERT INTO pr_events
+             (org_id, type, pull_request_id, team_name, user_ids, pull_request,
+              old_reviewer_id, new_reviewer_id, acted_by)
+         VALUES ($1, $2, $3,
+                 (SELECT t.team_name FROM users u JOIN teams t ON t.id = u.team_id
+                  WHERE u.org_id = $1 AND u.user_id = $4),
+                 $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))
+         RETURNING id, COALESCE(team_name, ''), created_at`,
+		e.OrgID, e.Type, e.PullRequest.ID, e.PullRequest.AuthorID, e.UserIDs(), pr,
+		e.OldReviewerID, e.NewReviewerID, e.ActedBy,
+	).Scan(&e.ID, &e.TeamName, &e.CreatedAt)
+}
+
+func (r *EventRepo) GetEvent(ctx context.Context, id int64) (*domain.Event, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+eventColumns+`
+         FROM pr_events
+         WHERE org_id = $1 AND id = $2`,
+		orgID(ctx), id,
+	)
+	e, err := scanEvent(row)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *EventRepo) ListEvents(ctx context.Context, f repository.EventFilter, afterID int64, limit int) ([]domain.Event, error) {
+	var b whereBuilder
+	b.add("org_id = " + b.arg(orgID(ctx)))
+	b.add("id > " + b.arg(afterID))
+	if f.PRID != "" {
+		b.add("pull_request_id = " + b.arg(f.PRID))
+	}
+	if f.TeamName != "" {
+		b.add("team_name = " + b.arg(f.TeamName))
+	}
+	if f.UserID != "" {
+		b.add(b.arg(f.UserID) + " = ANY(user_ids)")
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT `+eventColumns+`
+         FROM pr_events`+b.sql()+`
+         ORDER BY id
+         LIMIT `+b.arg(limit),
+		b.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.Event, 0)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *EventRepo) PruneEvents(ctx context.Context, before time.Time) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM pr_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanEvent(s interface{ Scan(dest ...any) error }) (domain.Event, error) {
+	var (
+		e  domain.Event
+		pr []byte
+	)
+	err := s.Scan(&e.ID, &e.OrgID, &e.Type, &pr, &e.TeamName,
+		&e.OldReviewerID, &e.NewReviewerID, &e.ActedBy, &e.CreatedAt)
+	if err != nil {
+		return e, err
+	}
+	return e, json.Unmarshal(pr, &e.PullRequest)
+}
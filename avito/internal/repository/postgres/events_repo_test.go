@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"avito/internal/db"
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"avito/internal/tenant"
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// testDB открывает базу из TEST_DATABASE_URL и накатывает миграции;
+// без переменной тест пропускается.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	conn, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := db.ApplyMigrations(context.Background(), conn); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	return conn
+}
+
+// TestAppendEventIDsFollowCommitOrder: транзакция, взявшая id раньше,
+// фиксируется позже. Второе событие ждёт её фиксации и получает больший
+// id, так что клиент, прочитавший его, уже видит и первое.
+func TestAppendEventIDsFollowCommitOrder(t *testing.T) {
+	conn := testDB(t)
+	repo := NewEventRepo(conn)
+	ctx := tenant.WithOrg(context.Background(), "default")
+	prID := "pr-order-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	event := domain.Event{
+		Type:        domain.EventPRCreated,
+		PullRequest: domain.PullRequest{ID: prID, AuthorID: "nobody"},
+	}
+
+	var first *domain.Event
+	appended := make(chan struct{})
+	commit := make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- NewTxManager(conn).WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			first, err = repo.AppendEvent(ctx, event)
+			close(appended)
+			if err != nil {
+				return err
+			}
+			<-commit
+			return nil
+		})
+	}()
+	<-appended
+
+	type result struct {
+		e   *domain.Event
+		err error
+	}
+	second := make(chan result, 1)
+	go func() {
+		e, err := repo.AppendEvent(ctx, event)
+		second <- result{e, err}
+	}()
+
+	select {
+	case r := <-second:
+		t.Fatalf("second event %+v (err %v) committed while the first is still open", r.e, r.err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(commit)
+	if err := <-txDone; err != nil {
+		t.Fatalf("first AppendEvent: %v", err)
+	}
+	r := <-second
+	if r.err != nil {
+		t.Fatalf("second AppendEvent: %v", r.err)
+	}
+	if r.e.ID <= first.ID {
+		t.Fatalf("second event id %d, want above the first %d", r.e.ID, first.ID)
+	}
+
+	events, err := repo.ListEvents(ctx, repository.EventFilter{PRID: prID}, 0, 10)
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != first.ID || events[1].ID != r.e.ID {
+		t.Fatalf("events = %+v, want ids %d and %d", events, first.ID, r.e.ID)
+	}
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"avito/internal/repository"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+func pageLimit(p repository.Page) int {
+	switch {
+	case p.Limit <= 0:
+		return defaultPageLimit
+	case p.Limit > maxPageLimit:
+		return maxPageLimit
+	default:
+		return p.Limit
+	}
+}
+
+// whereBuilder собирает условия WHERE с позиционными параметрами $1, $2, ...
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg добавляет значение в список параметров и возвращает его плейсхолдер.
+func (b *whereBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *whereBuilder) add(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func (b *whereBuilder) sql() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+// keyset описывает стабильную сортировку по (expr, idExpr): idExpr уникален
+// и разрешает равенство значений expr.
+type keyset struct {
+	sort    string
+	expr    string
+	keyType string
+	idExpr  string
+	desc    bool
+}
+
+type cursorPayload struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"id"`
+}
+
+func encodeCursor(sort, key, id string) string {
+	b, _ := json.Marshal(cursorPayload{Sort: sort, Key: key, ID: id})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(cursor, sort string) (cursorPayload, error) {
+	var c cursorPayload
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, repository.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort {
+		return c, repository.ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// apply добавляет условие «после курсора» и возвращает ORDER BY.
+func (k keyset) apply(b *whereBuilder, cursor string) (string, error) {
+	dir, cmp := "ASC", ">"
+	if k.desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if cursor != "" {
+		c, err := decodeCursor(cursor, k.sort)
+		if err != nil {
+			return "", err
+		}
+		b.add(fmt.Sprintf("(%s, %s) %s (%s::%s, %s)",
+			k.expr, k.idExpr, cmp, b.arg(c.Key), k.keyType, b.arg(c.ID)))
+	}
+
+	return fmt.Sprintf(" ORDER BY %s %s, %s %s", k.expr, dir, k.idExpr, dir), nil
+}
+
+// keyColumn — выражение, которое нужно выбрать, чтобы построить курсор.
+func (k keyset) keyColumn() string {
+	return "(" + k.expr + ")::text"
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+	"database/sql"
+)
+
+type OrgRepo struct {
+	db *sql.DB
+}
+
+func NewOrgRepo(db *sql.DB) *OrgRepo {
+	return &OrgRepo{db: db}
+}
+
+func (r *OrgRepo) CreateOrg(ctx context.Context, org domain.Organization) (*domain.Organization, error) {
+	created := org
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO organizations (id, name)
+         VALUES ($1, $2)
+         RETURNING created_at`,
+		org.ID, org.Name,
+	).Scan(&created.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, repository.ErrAlreadyExists
+		}
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (r *OrgRepo) GetOrg(ctx context.Context, id string) (*domain.Organization, error) {
+	var org domain.Organization
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id, name, created_at FROM organizations WHERE id = $1`,
+		id,
+	).Scan(&org.ID, &org.Name, &org.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrgRepo) ListOrgs(ctx context.Context) ([]domain.Organization, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT id, name, created_at FROM organizations ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := make([]domain.Organization, 0)
+	for rows.Next() {
+		var org domain.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
@@ -5,7 +5,10 @@ import (
 	"avito/internal/repository"
 	"context"
 	"database/sql"
+	"fmt"
 	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type PRRepo struct {
@@ -17,13 +20,14 @@ func NewPRRepo(db *sql.DB) *PRRepo {
 }
 
 func (r *PRRepo) GetOpenAssignmentsByTeam(ctx context.Context, teamName string) ([]repository.ReviewerAssignment, error) {
-	rows, err := r.db.QueryContext(ctx,
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
 		`SELECT pr.id, r.user_id
          FROM pull_requests pr
-         JOIN pull_request_reviewers r ON pr.id = r.pull_request_id
-         JOIN users u ON u.user_id = r.user_id
-         WHERE u.team_name = $1 AND pr.status = 'OPEN'`,
-		teamName,
+         JOIN pull_request_reviewers r ON r.org_id = pr.org_id AND r.pull_request_id = pr.id
+         JOIN users u ON u.org_id = r.org_id AND u.user_id = r.user_id
+         JOIN teams t ON t.id = u.team_id
+         WHERE pr.org_id = $1 AND t.team_name = $2 AND pr.status = 'OPEN'`,
+		orgID(ctx), teamName,
 	)
 	if err != nil {
 		return nil, err
@@ -41,68 +45,18 @@ func (r *PRRepo) GetOpenAssignmentsByTeam(ctx context.Context, teamName string)
 	return res, rows.Err()
 }
 
-func (r *PRRepo) GetStats(ctx context.Context) (repository.Stats, error) {
-	s := repository.Stats{
-		PerReviewer: make(map[string]int64),
-		PerStatus:   make(map[string]int64),
-	}
-
-	// per reviewer
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT user_id, COUNT(*)
-         FROM pull_request_reviewers
-         GROUP BY user_id`)
-	if err != nil {
-		return s, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var uid string
-		var cnt int64
-		if err := rows.Scan(&uid, &cnt); err != nil {
-			return s, err
-		}
-		s.PerReviewer[uid] = cnt
-	}
-	if err := rows.Err(); err != nil {
-		return s, err
-	}
-
-	// per status
-	rows, err = r.db.QueryContext(ctx,
-		`SELECT status, COUNT(*)
-         FROM pull_requests
-         GROUP BY status`)
-	if err != nil {
-		return s, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var status string
-		var cnt int64
-		if err := rows.Scan(&status, &cnt); err != nil {
-			return s, err
-		}
-		s.PerStatus[status] = cnt
-	}
-	return s, rows.Err()
+func (r *PRRepo) CreatePR(ctx context.Context, pr domain.PullRequest) error {
+	return inTx(ctx, r.db, func(q querier) error {
+		return createPR(ctx, q, pr)
+	})
 }
 
-func (r *PRRepo) CreatePR(pr domain.PullRequest) error {
-	ctx := context.Background()
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
+func createPR(ctx context.Context, q querier, pr domain.PullRequest) error {
 	// основная запись PR
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at)
-         VALUES ($1, $2, $3, $4, $5, $6)`,
-		pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt,
+	_, err := q.ExecContext(ctx,
+		`INSERT INTO pull_requests (org_id, id, name, author_id, status, created_at, merged_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		orgID(ctx), pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt,
 	)
 	if err != nil {
 		// проверка дубликата
@@ -113,30 +67,27 @@ func (r *PRRepo) CreatePR(pr domain.PullRequest) error {
 	}
 
 	// ревьюверы
-	for _, rid := range pr.AssignedReviewers {
-		_, err = tx.ExecContext(ctx,
-			`INSERT INTO pull_request_reviewers (pull_request_id, user_id)
-             VALUES ($1, $2)`,
-			pr.ID, rid,
-		)
-		if err != nil {
-			return err
-		}
-	}
+	return insertReviewers(ctx, q, pr.ID, pr.AssignedReviewers)
+}
 
-	return tx.Commit()
+func (r *PRRepo) GetPR(ctx context.Context, id string) (*domain.PullRequest, error) {
+	return getPR(ctx, conn(ctx, r.db), id, "")
 }
 
-func (r *PRRepo) GetPR(id string) (*domain.PullRequest, error) {
-	ctx := context.Background()
+// GetPRForUpdate — GetPR с блокировкой строки PR до конца транзакции из ctx:
+// параллельные изменения того же PR ждут её фиксации.
+func (r *PRRepo) GetPRForUpdate(ctx context.Context, id string) (*domain.PullRequest, error) {
+	return getPR(ctx, conn(ctx, r.db), id, " FOR UPDATE")
+}
 
+func getPR(ctx context.Context, q querier, id, lock string) (*domain.PullRequest, error) {
 	var pr domain.PullRequest
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, name, author_id, status, created_at, merged_at
+	err := q.QueryRowContext(ctx,
+		`SELECT id, name, author_id, status, created_at, merged_at, COALESCE(merged_by, '')
          FROM pull_requests
-         WHERE id = $1`,
-		id,
-	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt)
+         WHERE org_id = $1 AND id = $2`+lock,
+		orgID(ctx), id,
+	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.MergedBy)
 	if err == sql.ErrNoRows {
 		return nil, repository.ErrNotFound
 	}
@@ -145,11 +96,11 @@ func (r *PRRepo) GetPR(id string) (*domain.PullRequest, error) {
 	}
 
 	// подтягиваем ревьюверов
-	rows, err := r.db.QueryContext(ctx,
+	rows, err := q.QueryContext(ctx,
 		`SELECT user_id
          FROM pull_request_reviewers
-         WHERE pull_request_id = $1`,
-		id,
+         WHERE org_id = $1 AND pull_request_id = $2`,
+		orgID(ctx), id,
 	)
 	if err != nil {
 		return nil, err
@@ -172,23 +123,55 @@ func (r *PRRepo) GetPR(id string) (*domain.PullRequest, error) {
 	return &pr, nil
 }
 
-func (r *PRRepo) UpdatePR(pr domain.PullRequest) error {
-	ctx := context.Background()
-	tx, err := r.db.BeginTx(ctx, nil)
+func (r *PRRepo) GetPRs(ctx context.Context, ids []string) ([]domain.PullRequest, error) {
+	q := conn(ctx, r.db)
+	rows, err := q.QueryContext(ctx,
+		`SELECT id, name, author_id, status, created_at, merged_at, COALESCE(merged_by, '')
+         FROM pull_requests
+         WHERE org_id = $1 AND id = ANY($2)`,
+		orgID(ctx), ids,
+	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	prs := make([]domain.PullRequest, 0, len(ids))
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.MergedBy); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	res, err := tx.ExecContext(ctx,
+	if err := fillReviewers(ctx, q, prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+func (r *PRRepo) UpdatePR(ctx context.Context, pr domain.PullRequest) error {
+	return inTx(ctx, r.db, func(q querier) error {
+		return updatePR(ctx, q, pr)
+	})
+}
+
+func updatePR(ctx context.Context, q querier, pr domain.PullRequest) error {
+	res, err := q.ExecContext(ctx,
 		`UPDATE pull_requests
-         SET name = $2,
-             author_id = $3,
-             status = $4,
-             created_at = $5,
-             merged_at = $6
-         WHERE id = $1`,
-		pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt,
+         SET name = $3,
+             author_id = $4,
+             status = $5,
+             created_at = $6,
+             merged_at = $7,
+             merged_by = NULLIF($8, '')
+         WHERE org_id = $1 AND id = $2`,
+		orgID(ctx), pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt, pr.MergedBy,
 	)
 	if err != nil {
 		return err
@@ -199,52 +182,231 @@ func (r *PRRepo) UpdatePR(pr domain.PullRequest) error {
 	}
 
 	// пересоздаём список ревьюверов
-	_, err = tx.ExecContext(ctx,
+	_, err = q.ExecContext(ctx,
 		`DELETE FROM pull_request_reviewers
-         WHERE pull_request_id = $1`,
-		pr.ID,
+         WHERE org_id = $1 AND pull_request_id = $2`,
+		orgID(ctx), pr.ID,
 	)
 	if err != nil {
 		return err
 	}
 
-	for _, rid := range pr.AssignedReviewers {
-		_, err = tx.ExecContext(ctx,
-			`INSERT INTO pull_request_reviewers (pull_request_id, user_id)
-             VALUES ($1, $2)`,
-			pr.ID, rid,
+	return insertReviewers(ctx, q, pr.ID, pr.AssignedReviewers)
+}
+
+func insertReviewers(ctx context.Context, q querier, prID string, reviewers []string) error {
+	for _, rid := range reviewers {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO pull_request_reviewers (org_id, pull_request_id, user_id)
+             VALUES ($1, $2, $3)`,
+			orgID(ctx), prID, rid,
 		)
 		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// GetPRsByReviewer возвращает страницу PR, где пользователь назначен
+// ревьювером, и счётчики по всей выборке.
+func (r *PRRepo) GetPRsByReviewer(ctx context.Context, userID string, f repository.ReviewFilter) (repository.ReviewPage, error) {
+	res := repository.ReviewPage{
+		TotalByStatus: make(map[domain.PullRequestStatus]int64),
+	}
+
+	pf := repository.PRFilter{
+		ReviewerID: userID,
+		Sort:       f.Sort,
+		Order:      f.Order,
+		Page:       f.Page,
+	}
+	if f.Sort == repository.PRSortByMergedAt {
+		pf.MergedFrom, pf.MergedTo = f.Since, f.Until
+	} else {
+		pf.CreatedFrom, pf.CreatedTo = f.Since, f.Until
+	}
+
+	// счётчики и страница читаются на одном снимке: иначе параллельный
+	// merge или переназначение между запросами рассогласует их
+	err := readSnapshot(ctx, r.db, func(ctx context.Context) error {
+		// счётчики по статусам считаются без фильтра по статусу
+		var b whereBuilder
+		prWhere(ctx, &b, pf)
+		rows, err := conn(ctx, r.db).QueryContext(ctx,
+			`SELECT pr.status, COUNT(*)
+             FROM pull_requests pr`+
+				b.sql()+
+				` GROUP BY pr.status`,
+			b.args...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var status domain.PullRequestStatus
+			var cnt int64
+			if err := rows.Scan(&status, &cnt); err != nil {
+				rows.Close()
+				return err
+			}
+			res.TotalByStatus[status] = cnt
+			if f.Status == "" || f.Status == status {
+				res.Total += cnt
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
 
-	return tx.Commit()
+		pf.Status = f.Status
+		page, err := r.ListPRs(ctx, pf)
+		if err != nil {
+			return err
+		}
+		res.Items = page.Items
+		res.NextCursor = page.NextCursor
+		return nil
+	})
+	return res, err
 }
 
-func (r *PRRepo) GetPRsByReviewer(userID string) ([]domain.PullRequest, error) {
-	ctx := context.Background()
+// GetOpenPRsByReviewer возвращает открытые PR, где пользователь назначен
+// ревьювером, вместе с полным списком их ревьюверов.
+func (r *PRRepo) GetOpenPRsByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	q := conn(ctx, r.db)
+	rows, err := q.QueryContext(ctx,
+		`SELECT pr.id, pr.name, pr.author_id, pr.status, pr.created_at, pr.merged_at, COALESCE(pr.merged_by, '')
+         FROM pull_requests pr
+         JOIN pull_request_reviewers r
+           ON r.org_id = pr.org_id AND r.pull_request_id = pr.id
+         WHERE pr.org_id = $1 AND r.user_id = $2 AND pr.status = 'OPEN'
+         ORDER BY pr.id`,
+		orgID(ctx), userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]domain.PullRequest, 0)
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.MergedBy); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := fillReviewers(ctx, q, prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
 
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT pr.id, pr.name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+func (r *PRRepo) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) (map[string][]domain.PullRequest, error) {
+	q := conn(ctx, r.db)
+	rows, err := q.QueryContext(ctx,
+		`SELECT r.user_id, pr.id, pr.name, pr.author_id, pr.status, pr.created_at, pr.merged_at, COALESCE(pr.merged_by, '')
          FROM pull_requests pr
          JOIN pull_request_reviewers r
-           ON pr.id = r.pull_request_id
-         WHERE r.user_id = $1`,
-		userID,
+           ON r.org_id = pr.org_id AND r.pull_request_id = pr.id
+         WHERE pr.org_id = $1 AND r.user_id = ANY($2) AND pr.status = 'OPEN'
+         ORDER BY r.user_id, pr.id`,
+		orgID(ctx), userIDs,
 	)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	res := make([]domain.PullRequest, 0)
+	// один PR может встречаться у нескольких ревьюверов: ревьюверов
+	// подтягиваем для списка без повторов, а затем раскладываем по ключам
+	var reviewers []string
+	var prs []domain.PullRequest
 	for rows.Next() {
+		var uid string
 		var pr domain.PullRequest
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
+		if err := rows.Scan(&uid, &pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.MergedBy); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		reviewers = append(reviewers, uid)
+		prs = append(prs, pr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	unique := make([]domain.PullRequest, 0, len(prs))
+	idx := make(map[string]int, len(prs))
+	for _, pr := range prs {
+		if _, ok := idx[pr.ID]; !ok {
+			idx[pr.ID] = len(unique)
+			unique = append(unique, pr)
+		}
+	}
+	if err := fillReviewers(ctx, q, unique); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]domain.PullRequest, len(userIDs))
+	for i, uid := range reviewers {
+		res[uid] = append(res[uid], unique[idx[prs[i].ID]])
+	}
+	return res, nil
+}
+
+func (r *PRRepo) RecordAssignment(ctx context.Context, d domain.AssignmentDecision) error {
+	var replaced *string
+	if d.ReplacedUserID != "" {
+		replaced = &d.ReplacedUserID
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO assignment_decisions
+             (org_id, pull_request_id, action, strategy, seed, candidates, assigned, replaced_user_id, acted_by, decided_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10)`,
+		orgID(ctx), d.PRID, d.Action, d.Strategy, d.Seed, d.Candidates, d.Assigned, replaced, d.ActedBy, d.DecidedAt,
+	)
+	return err
+}
+
+func (r *PRRepo) GetAssignmentDecisions(ctx context.Context, prID string) ([]domain.AssignmentDecision, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT pull_request_id, action, strategy, seed, candidates, assigned,
+                COALESCE(replaced_user_id, ''), COALESCE(acted_by, ''), decided_at
+         FROM assignment_decisions
+         WHERE org_id = $1 AND pull_request_id = $2
+         ORDER BY id`,
+		orgID(ctx), prID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// pgtype.Map нужен для сканирования TEXT[] через database/sql;
+	// он не потокобезопасен, поэтому создаётся на каждый запрос
+	typeMap := pgtype.NewMap()
+
+	res := make([]domain.AssignmentDecision, 0)
+	for rows.Next() {
+		var d domain.AssignmentDecision
+		err := rows.Scan(
+			&d.PRID, &d.Action, &d.Strategy, &d.Seed,
+			typeMap.SQLScanner(&d.Candidates), typeMap.SQLScanner(&d.Assigned),
+			&d.ReplacedUserID, &d.ActedBy, &d.DecidedAt,
+		)
+		if err != nil {
 			return nil, err
 		}
-		res = append(res, pr)
+		res = append(res, d)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -256,3 +418,145 @@ func (r *PRRepo) GetPRsByReviewer(userID string) ([]domain.PullRequest, error) {
 func isUniqueViolation(err error) bool {
 	return strings.Contains(err.Error(), "duplicate key")
 }
+
+func (r *PRRepo) ListPRs(ctx context.Context, f repository.PRFilter) (repository.PRPage, error) {
+	var page repository.PRPage
+
+	ks := keyset{
+		sort:    string(repository.PRSortByID),
+		expr:    "pr.id",
+		keyType: "text",
+		idExpr:  "pr.id",
+		desc:    f.Order == repository.SortDesc,
+	}
+	// выражения сортировки совпадают с индексами из миграции 012
+	switch f.Sort {
+	case repository.PRSortByCreatedAt:
+		ks.sort, ks.expr, ks.keyType = string(f.Sort), "COALESCE(pr.created_at, '-infinity')", "timestamptz"
+	case repository.PRSortByMergedAt:
+		// открытые PR без merged_at идут после всех смёрженных
+		ks.sort, ks.expr, ks.keyType = string(f.Sort), "COALESCE(pr.merged_at, 'infinity')", "timestamptz"
+	}
+
+	var b whereBuilder
+	prWhere(ctx, &b, f)
+	orderBy, err := ks.apply(&b, f.Cursor)
+	if err != nil {
+		return page, err
+	}
+	limit := pageLimit(f.Page)
+
+	q := conn(ctx, r.db)
+	rows, err := q.QueryContext(ctx,
+		`SELECT pr.id, pr.name, pr.author_id, pr.status, pr.created_at, pr.merged_at, COALESCE(pr.merged_by, ''), `+ks.keyColumn()+`
+         FROM pull_requests pr`+
+			b.sql()+
+			orderBy+
+			fmt.Sprintf(" LIMIT %d", limit+1),
+		b.args...,
+	)
+	if err != nil {
+		return page, err
+	}
+
+	page.Items = make([]domain.PullRequest, 0, limit)
+	var lastKey string
+	for rows.Next() {
+		var pr domain.PullRequest
+		var key string
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.MergedBy, &key); err != nil {
+			rows.Close()
+			return page, err
+		}
+		if len(page.Items) < limit {
+			lastKey = key
+		}
+		page.Items = append(page.Items, pr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(ks.sort, lastKey, page.Items[limit-1].ID)
+	}
+
+	if err := fillReviewers(ctx, q, page.Items); err != nil {
+		return page, err
+	}
+	return page, nil
+}
+
+// fillReviewers подтягивает ревьюверов для набора PR одним запросом.
+func fillReviewers(ctx context.Context, q querier, prs []domain.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(prs))
+	idx := make(map[string]int, len(prs))
+	for i := range prs {
+		prs[i].AssignedReviewers = make([]string, 0)
+		ids = append(ids, prs[i].ID)
+		idx[prs[i].ID] = i
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT pull_request_id, user_id
+         FROM pull_request_reviewers
+         WHERE org_id = $1 AND pull_request_id = ANY($2)
+         ORDER BY pull_request_id, user_id`,
+		orgID(ctx), ids,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var prID, uid string
+		if err := rows.Scan(&prID, &uid); err != nil {
+			return err
+		}
+		i := idx[prID]
+		prs[i].AssignedReviewers = append(prs[i].AssignedReviewers, uid)
+	}
+	return rows.Err()
+}
+
+// prWhere добавляет в b условия фильтра PR (без курсора), начиная
+// с организации запроса.
+func prWhere(ctx context.Context, b *whereBuilder, f repository.PRFilter) {
+	b.add("pr.org_id = " + b.arg(orgID(ctx)))
+	if f.Status != "" {
+		b.add("pr.status = " + b.arg(string(f.Status)))
+	}
+	if f.AuthorID != "" {
+		b.add("pr.author_id = " + b.arg(f.AuthorID))
+	}
+	if f.ReviewerID != "" {
+		b.add(`EXISTS (SELECT 1 FROM pull_request_reviewers r
+                       WHERE r.org_id = pr.org_id AND r.pull_request_id = pr.id
+                         AND r.user_id = ` + b.arg(f.ReviewerID) + `)`)
+	}
+	if f.TeamName != "" {
+		b.add(`pr.author_id IN (SELECT u.user_id FROM users u
+                                JOIN teams t ON t.id = u.team_id
+                                WHERE u.org_id = pr.org_id
+                                  AND t.team_name = ` + b.arg(f.TeamName) + `)`)
+	}
+	if f.CreatedFrom != nil {
+		b.add("pr.created_at >= " + b.arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		b.add("pr.created_at < " + b.arg(*f.CreatedTo))
+	}
+	if f.MergedFrom != nil {
+		b.add("pr.merged_at >= " + b.arg(*f.MergedFrom))
+	}
+	if f.MergedTo != nil {
+		b.add("pr.merged_at < " + b.arg(*f.MergedTo))
+	}
+}
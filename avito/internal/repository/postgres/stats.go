@@ -0,0 +1,402 @@
+package postgres
+
+import (
+	"avito/internal/repository"
+	"context"
+	"sort"
+	"time"
+)
+
+// statsWindow добавляет условия организации запроса, окна по колонке col
+// и фильтр по команде. Запрос должен выбирать PR под псевдонимом pr.
+func statsWindow(ctx context.Context, b *whereBuilder, f repository.StatsFilter, col, teamExpr string) {
+	b.add("pr.org_id = " + b.arg(orgID(ctx)))
+	if f.From != nil {
+		b.add(col + " >= " + b.arg(*f.From))
+	}
+	if f.To != nil {
+		b.add(col + " < " + b.arg(*f.To))
+	}
+	if f.TeamName != "" {
+		b.add(teamExpr + " = " + b.arg(f.TeamName))
+	}
+}
+
+func (r *PRRepo) GetStats(ctx context.Context, f repository.StatsFilter) (repository.Stats, error) {
+	s := repository.Stats{
+		PerReviewer: make(map[string]int64),
+		PerStatus:   make(map[string]int64),
+	}
+	teams := make(map[string]*repository.TeamStats)
+	team := func(name string) *repository.TeamStats {
+		t, ok := teams[name]
+		if !ok {
+			t = &repository.TeamStats{
+				TeamName:            name,
+				PerReviewer:         make(map[string]int64),
+				PerStatus:           make(map[string]int64),
+				OpenReviewsPerUser:  make(map[string]int64),
+				InactiveWithReviews: make(map[string]int64),
+				Trend:               make([]repository.TrendPoint, 0),
+			}
+			teams[name] = t
+		}
+		return t
+	}
+	q := conn(ctx, r.db)
+
+	// per reviewer: назначения на PR, созданные в окне, по команде ревьювера
+	var b whereBuilder
+	statsWindow(ctx, &b, f, "pr.created_at", "t.team_name")
+	rows, err := q.QueryContext(ctx,
+		`SELECT COALESCE(t.team_name, ''), r.user_id, COUNT(*)
+         FROM pull_request_reviewers r
+         JOIN pull_requests pr ON pr.org_id = r.org_id AND pr.id = r.pull_request_id
+         JOIN users u ON u.org_id = r.org_id AND u.user_id = r.user_id
+         LEFT JOIN teams t ON t.id = u.team_id`+
+			b.sql()+
+			` GROUP BY 1, 2`,
+		b.args...,
+	)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var teamName, uid string
+		var cnt int64
+		if err := rows.Scan(&teamName, &uid, &cnt); err != nil {
+			rows.Close()
+			return s, err
+		}
+		team(teamName).PerReviewer[uid] = cnt
+		s.PerReviewer[uid] += cnt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	// per status: PR, созданные в окне, по команде автора
+	b = whereBuilder{}
+	statsWindow(ctx, &b, f, "pr.created_at", "t.team_name")
+	rows, err = q.QueryContext(ctx,
+		`SELECT COALESCE(t.team_name, ''), pr.status, COUNT(*)
+         FROM pull_requests pr
+         JOIN users u ON u.org_id = pr.org_id AND u.user_id = pr.author_id
+         LEFT JOIN teams t ON t.id = u.team_id`+
+			b.sql()+
+			` GROUP BY 1, 2`,
+		b.args...,
+	)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var teamName, status string
+		var cnt int64
+		if err := rows.Scan(&teamName, &status, &cnt); err != nil {
+			rows.Close()
+			return s, err
+		}
+		team(teamName).PerStatus[status] = cnt
+		s.PerStatus[status] += cnt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	// открытые ревью на пользователя — текущее состояние, без окна
+	b = whereBuilder{}
+	b.add("pr.org_id = " + b.arg(orgID(ctx)))
+	b.add("pr.status = 'OPEN'")
+	if f.TeamName != "" {
+		b.add("t.team_name = " + b.arg(f.TeamName))
+	}
+	rows, err = q.QueryContext(ctx,
+		`SELECT COALESCE(t.team_name, ''), r.user_id, u.is_active, COUNT(*)
+         FROM pull_request_reviewers r
+         JOIN pull_requests pr ON pr.org_id = r.org_id AND pr.id = r.pull_request_id
+         JOIN users u ON u.org_id = r.org_id AND u.user_id = r.user_id
+         LEFT JOIN teams t ON t.id = u.team_id`+
+			b.sql()+
+			` GROUP BY 1, 2, 3`,
+		b.args...,
+	)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var teamName, uid string
+		var active bool
+		var cnt int64
+		if err := rows.Scan(&teamName, &uid, &active, &cnt); err != nil {
+			rows.Close()
+			return s, err
+		}
+		t := team(teamName)
+		t.OpenReviewsPerUser[uid] = cnt
+		if !active {
+			t.InactiveWithReviews[uid] = cnt
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	// тренд: созданные и смёрженные PR по периодам, по команде автора
+	if err := fillTrend(ctx, q, f, team); err != nil {
+		return s, err
+	}
+
+	names := make([]string, 0, len(teams))
+	for name := range teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s.Teams = make([]repository.TeamStats, 0, len(names))
+	for _, name := range names {
+		s.Teams = append(s.Teams, *teams[name])
+	}
+	return s, nil
+}
+
+func fillTrend(
+	ctx context.Context,
+	q querier,
+	f repository.StatsFilter,
+	team func(string) *repository.TeamStats,
+) error {
+	bucket := f.Bucket
+	if bucket == "" {
+		bucket = repository.StatsBucketDay
+	}
+
+	var created, merged whereBuilder
+	created.arg(string(bucket))
+	merged.arg(string(bucket))
+	statsWindow(ctx, &created, f, "pr.created_at", "t.team_name")
+	merged.add("pr.merged_at IS NOT NULL")
+	statsWindow(ctx, &merged, f, "pr.merged_at", "t.team_name")
+
+	// созданные и смёрженные считаются отдельно: у них разные колонки окна
+	for _, part := range []struct {
+		b   *whereBuilder
+		col string
+	}{
+		{&created, "pr.created_at"},
+		{&merged, "pr.merged_at"},
+	} {
+		rows, err := q.QueryContext(ctx,
+			`SELECT COALESCE(t.team_name, ''),
+                    date_trunc($1, `+part.col+` AT TIME ZONE 'UTC') AS period,
+                    COUNT(*)
+             FROM pull_requests pr
+             JOIN users u ON u.org_id = pr.org_id AND u.user_id = pr.author_id
+             LEFT JOIN teams t ON t.id = u.team_id`+
+				part.b.sql()+
+				` GROUP BY 1, 2`,
+			part.b.args...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var teamName string
+			var period time.Time
+			var cnt int64
+			if err := rows.Scan(&teamName, &period, &cnt); err != nil {
+				rows.Close()
+				return err
+			}
+			addTrend(team(teamName), period.UTC(), cnt, part.col == "pr.merged_at")
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTrend добавляет значение в точку тренда, сохраняя сортировку по периоду.
+func addTrend(t *repository.TeamStats, period time.Time, cnt int64, merged bool) {
+	i := sort.Search(len(t.Trend), func(i int) bool {
+		return !t.Trend[i].Period.Before(period)
+	})
+	if i == len(t.Trend) || !t.Trend[i].Period.Equal(period) {
+		t.Trend = append(t.Trend, repository.TrendPoint{})
+		copy(t.Trend[i+1:], t.Trend[i:])
+		t.Trend[i] = repository.TrendPoint{Period: period}
+	}
+	if merged {
+		t.Trend[i].Merged += cnt
+	} else {
+		t.Trend[i].Created += cnt
+	}
+}
+
+// GetMergeLatency считает перцентили времени до merge агрегатами
+// percentile_cont на стороне Postgres.
+func (r *PRRepo) GetMergeLatency(ctx context.Context, f repository.LatencyFilter) (repository.MergeLatency, error) {
+	res := repository.MergeLatency{
+		PerTeam:   make([]repository.TeamLatency, 0),
+		PerAuthor: make([]repository.AuthorLatency, 0),
+	}
+
+	var b whereBuilder
+	b.add("pr.status = 'MERGED'")
+	b.add("pr.merged_at IS NOT NULL")
+	b.add("pr.created_at IS NOT NULL")
+	statsWindow(ctx, &b, repository.StatsFilter{From: f.From, To: f.To, TeamName: f.TeamName},
+		"pr.merged_at", "t.team_name")
+
+	// GROUPING SETS даёт обе разбивки одним проходом; GROUPING(pr.author_id)
+	// равен 1 для строк по команде целиком
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`WITH merged AS (
+             SELECT COALESCE(t.team_name, '') AS team_name,
+                    pr.author_id,
+                    EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)::float8 AS seconds
+             FROM pull_requests pr
+             JOIN users u ON u.org_id = pr.org_id AND u.user_id = pr.author_id
+             LEFT JOIN teams t ON t.id = u.team_id`+
+			b.sql()+`
+         )
+         SELECT team_name,
+                COALESCE(author_id, ''),
+                GROUPING(author_id),
+                COUNT(*),
+                percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds),
+                percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds),
+                percentile_cont(0.99) WITHIN GROUP (ORDER BY seconds)
+         FROM merged
+         GROUP BY GROUPING SETS ((team_name), (team_name, author_id))
+         ORDER BY team_name, GROUPING(author_id) DESC, author_id`,
+		b.args...,
+	)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teamName, authorID string
+		var teamRow int
+		var p repository.LatencyPercentiles
+		if err := rows.Scan(&teamName, &authorID, &teamRow, &p.Count, &p.P50, &p.P90, &p.P99); err != nil {
+			return res, err
+		}
+		if teamRow == 1 {
+			res.PerTeam = append(res.PerTeam, repository.TeamLatency{
+				TeamName:           teamName,
+				LatencyPercentiles: p,
+			})
+			continue
+		}
+		res.PerAuthor = append(res.PerAuthor, repository.AuthorLatency{
+			AuthorID:           authorID,
+			TeamName:           teamName,
+			LatencyPercentiles: p,
+		})
+	}
+	return res, rows.Err()
+}
+
+// GetReviewLoad возвращает нагрузку текущих участников команды за окно
+// [from, to). Активные дни считаются по журналу user_activity_changes.
+func (r *PRRepo) GetReviewLoad(ctx context.Context, teamName string, from, to time.Time) ([]repository.MemberLoad, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`WITH members AS (
+             SELECT u.user_id, u.is_active
+             FROM users u
+             JOIN teams t ON t.id = u.team_id
+             WHERE u.org_id = $4 AND t.team_name = $1
+         ),
+         periods AS (
+             SELECT c.user_id, c.is_active, c.changed_at,
+                    LEAD(c.changed_at, 1, 'infinity') OVER (
+                        PARTITION BY c.user_id ORDER BY c.changed_at, c.id
+                    ) AS next_at
+             FROM user_activity_changes c
+             JOIN members m ON m.user_id = c.user_id
+             WHERE c.org_id = $4
+         ),
+         active AS (
+             SELECT user_id,
+                    SUM(EXTRACT(EPOCH FROM LEAST(next_at, $3) - GREATEST(changed_at, $2)))::float8 / 86400 AS days
+             FROM periods
+             WHERE is_active AND changed_at < $3 AND next_at > $2
+             GROUP BY user_id
+         ),
+         assigned AS (
+             SELECT r.user_id, COUNT(*) AS cnt
+             FROM pull_request_reviewers r
+             JOIN pull_requests pr ON pr.org_id = r.org_id AND pr.id = r.pull_request_id
+             JOIN members m ON m.user_id = r.user_id
+             WHERE r.org_id = $4 AND pr.created_at >= $2 AND pr.created_at < $3
+             GROUP BY r.user_id
+         )
+         SELECT m.user_id, m.is_active, COALESCE(a.cnt, 0), COALESCE(ac.days, 0)
+         FROM members m
+         LEFT JOIN assigned a ON a.user_id = m.user_id
+         LEFT JOIN active ac ON ac.user_id = m.user_id
+         ORDER BY m.user_id`,
+		teamName, from, to, orgID(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]repository.MemberLoad, 0)
+	for rows.Next() {
+		var m repository.MemberLoad
+		if err := rows.Scan(&m.UserID, &m.IsActive, &m.Assignments, &m.DaysActive); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// GetOpenCountsByTeam возвращает число открытых PR и ревью по командам
+// всех организаций — для метрик процесса. Пользователи без команды
+// попадают под пустое имя.
+func (r *PRRepo) GetOpenCountsByTeam(ctx context.Context) (map[repository.OrgTeam]repository.OpenCounts, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT org_id, team_name, SUM(prs)::bigint, SUM(reviews)::bigint
+         FROM (
+             SELECT pr.org_id, COALESCE(t.team_name, '') AS team_name, COUNT(*) AS prs, 0 AS reviews
+             FROM pull_requests pr
+             JOIN users u ON u.org_id = pr.org_id AND u.user_id = pr.author_id
+             LEFT JOIN teams t ON t.id = u.team_id
+             WHERE pr.status = 'OPEN'
+             GROUP BY 1, 2
+             UNION ALL
+             SELECT r.org_id, COALESCE(t.team_name, ''), 0, COUNT(*)
+             FROM pull_request_reviewers r
+             JOIN pull_requests pr ON pr.org_id = r.org_id AND pr.id = r.pull_request_id
+             JOIN users u ON u.org_id = r.org_id AND u.user_id = r.user_id
+             LEFT JOIN teams t ON t.id = u.team_id
+             WHERE pr.status = 'OPEN'
+             GROUP BY 1, 2
+         ) c
+         GROUP BY org_id, team_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[repository.OrgTeam]repository.OpenCounts)
+	for rows.Next() {
+		var key repository.OrgTeam
+		var c repository.OpenCounts
+		if err := rows.Scan(&key.OrgID, &key.TeamName, &c.PullRequests, &c.Reviews); err != nil {
+			return nil, err
+		}
+		res[key] = c
+	}
+	return res, rows.Err()
+}
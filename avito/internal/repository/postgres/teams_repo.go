@@ -5,6 +5,7 @@ import (
 	"avito/internal/repository"
 	"context"
 	"database/sql"
+	"fmt"
 )
 
 type TeamRepo struct {
@@ -15,45 +16,30 @@ func NewTeamRepo(db *sql.DB) *TeamRepo {
 	return &TeamRepo{db: db}
 }
 
-func (r *TeamRepo) CreateTeam(team domain.Team) error {
-	ctx := context.Background()
-
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO teams (team_name) VALUES ($1)`,
-		team.TeamName,
-	)
-	if err != nil {
-		return err
-	}
-
-	// вставляем/обновляем пользователей
-	for _, m := range team.Members {
-		_, err := r.db.ExecContext(ctx,
-			`INSERT INTO users (user_id, username, team_name, is_active)
-             VALUES ($1, $2, $3, $4)
-             ON CONFLICT (user_id) DO UPDATE
-               SET username = EXCLUDED.username,
-                   team_name = EXCLUDED.team_name,
-                   is_active = EXCLUDED.is_active`,
-			m.UserID, m.Username, team.TeamName, m.IsActive,
+func (r *TeamRepo) CreateTeam(ctx context.Context, team domain.Team) error {
+	return inTx(ctx, r.db, func(q querier) error {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO teams (org_id, team_name) VALUES ($1, $2)`,
+			orgID(ctx), team.TeamName,
 		)
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
+		// вставляем/обновляем пользователей
+		return upsertMembers(ctx, q, team.TeamName, team.Members)
+	})
 }
 
-func (r *TeamRepo) GetTeam(name string) (*domain.Team, error) {
-	ctx := context.Background()
+func (r *TeamRepo) GetTeam(ctx context.Context, name string) (*domain.Team, error) {
+	q := conn(ctx, r.db)
 
 	// проверяем, что команда существует
-	var teamName string
-	err := r.db.QueryRowContext(ctx,
-		`SELECT team_name FROM teams WHERE team_name = $1`,
-		name,
-	).Scan(&teamName)
+	var teamID int64
+	err := q.QueryRowContext(ctx,
+		`SELECT id FROM teams WHERE org_id = $1 AND team_name = $2`,
+		orgID(ctx), name,
+	).Scan(&teamID)
 	if err == sql.ErrNoRows {
 		return nil, repository.ErrNotFound
 	}
@@ -61,11 +47,11 @@ func (r *TeamRepo) GetTeam(name string) (*domain.Team, error) {
 		return nil, err
 	}
 
-	rows, err := r.db.QueryContext(ctx,
+	rows, err := q.QueryContext(ctx,
 		`SELECT user_id, username, is_active
          FROM users
-         WHERE team_name = $1`,
-		name,
+         WHERE org_id = $1 AND team_id = $2`,
+		orgID(ctx), teamID,
 	)
 	if err != nil {
 		return nil, err
@@ -85,7 +71,214 @@ func (r *TeamRepo) GetTeam(name string) (*domain.Team, error) {
 	}
 
 	return &domain.Team{
+		ID:       teamID,
 		TeamName: name,
 		Members:  members,
 	}, nil
 }
+
+func (r *TeamRepo) GetTeams(ctx context.Context, names []string) ([]domain.Team, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT t.id, t.team_name, u.user_id, u.username, u.is_active
+         FROM teams t
+         LEFT JOIN users u ON u.org_id = t.org_id AND u.team_id = t.id
+         WHERE t.org_id = $1 AND t.team_name = ANY($2)
+         ORDER BY t.team_name`,
+		orgID(ctx), names,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]domain.Team, 0, len(names))
+	for rows.Next() {
+		var id int64
+		var name string
+		var userID, username sql.NullString
+		var isActive sql.NullBool
+		if err := rows.Scan(&id, &name, &userID, &username, &isActive); err != nil {
+			return nil, err
+		}
+		if len(teams) == 0 || teams[len(teams)-1].TeamName != name {
+			teams = append(teams, domain.Team{ID: id, TeamName: name, Members: make([]domain.TeamMember, 0)})
+		}
+		// команда без участников даёт одну строку с NULL вместо пользователя
+		if userID.Valid {
+			t := &teams[len(teams)-1]
+			t.Members = append(t.Members, domain.TeamMember{
+				UserID:   userID.String,
+				Username: username.String,
+				IsActive: isActive.Bool,
+			})
+		}
+	}
+	return teams, rows.Err()
+}
+
+func (r *TeamRepo) AddMembers(ctx context.Context, teamName string, members []domain.TeamMember) error {
+	return inTx(ctx, r.db, func(q querier) error {
+		return upsertMembers(ctx, q, teamName, members)
+	})
+}
+
+// RemoveMembers выводит пользователей из команды: team_id обнуляется.
+// Активность не меняется: без команды пользователь и так не попадает
+// в кандидаты на ревью.
+func (r *TeamRepo) RemoveMembers(ctx context.Context, teamName string, userIDs []string) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+         SET team_id = NULL
+         WHERE org_id = $1
+           AND team_id = (SELECT id FROM teams WHERE org_id = $1 AND team_name = $2)
+           AND user_id = ANY($3)`,
+		orgID(ctx), teamName, userIDs,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *TeamRepo) MoveMember(ctx context.Context, userID, toTeamName string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+         SET team_id = (SELECT id FROM teams WHERE org_id = $1 AND team_name = $3)
+         WHERE org_id = $1 AND user_id = $2`,
+		orgID(ctx), userID, toTeamName,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err == nil && rows == 0 {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// MoveAllMembers переводит всех участников команды fromTeamName в toTeamName.
+func (r *TeamRepo) MoveAllMembers(ctx context.Context, fromTeamName, toTeamName string) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+         SET team_id = (SELECT id FROM teams WHERE org_id = $1 AND team_name = $3)
+         WHERE org_id = $1
+           AND team_id = (SELECT id FROM teams WHERE org_id = $1 AND team_name = $2)`,
+		orgID(ctx), fromTeamName, toTeamName,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RenameTeam меняет имя команды. Пользователи ссылаются на суррогатный id,
+// поэтому ссылки обновлять не нужно.
+func (r *TeamRepo) RenameTeam(ctx context.Context, oldName, newName string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE teams SET team_name = $3 WHERE org_id = $1 AND team_name = $2`,
+		orgID(ctx), oldName, newName,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrAlreadyExists
+		}
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err == nil && rows == 0 {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// DeleteTeam удаляет команду. Внешний ключ users.team_id не даст удалить
+// команду, в которой ещё остались участники.
+func (r *TeamRepo) DeleteTeam(ctx context.Context, name string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM teams WHERE org_id = $1 AND team_name = $2`,
+		orgID(ctx), name,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err == nil && rows == 0 {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+func upsertMembers(ctx context.Context, q querier, teamName string, members []domain.TeamMember) error {
+	for _, m := range members {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO users (org_id, user_id, username, team_id, is_active)
+             VALUES ($1, $2, $3, (SELECT id FROM teams WHERE org_id = $1 AND team_name = $4), $5)
+             ON CONFLICT (org_id, user_id) DO UPDATE
+               SET username = EXCLUDED.username,
+                   team_id = EXCLUDED.team_id,
+                   is_active = EXCLUDED.is_active`,
+			orgID(ctx), m.UserID, m.Username, teamName, m.IsActive,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TeamRepo) ListTeams(ctx context.Context, f repository.TeamFilter) (repository.TeamPage, error) {
+	var page repository.TeamPage
+
+	ks := keyset{
+		sort:    "team_name",
+		expr:    "t.team_name",
+		keyType: "text",
+		idExpr:  "t.team_name",
+		desc:    f.Order == repository.SortDesc,
+	}
+
+	var b whereBuilder
+	b.add("t.org_id = " + b.arg(orgID(ctx)))
+	orderBy, err := ks.apply(&b, f.Cursor)
+	if err != nil {
+		return page, err
+	}
+	limit := pageLimit(f.Page)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT t.team_name,
+                COUNT(u.user_id),
+                COUNT(u.user_id) FILTER (WHERE u.is_active)
+         FROM teams t
+         LEFT JOIN users u ON u.team_id = t.id`+
+			b.sql()+
+			` GROUP BY t.id, t.team_name`+
+			orderBy+
+			fmt.Sprintf(" LIMIT %d", limit+1),
+		b.args...,
+	)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = make([]repository.TeamSummary, 0, limit)
+	for rows.Next() {
+		var t repository.TeamSummary
+		if err := rows.Scan(&t.TeamName, &t.Members, &t.ActiveMembers); err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(ks.sort, last.TeamName, last.TeamName)
+	}
+	return page, nil
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"avito/internal/logging"
+	"avito/internal/tenant"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// querier — общее подмножество *sql.DB и *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// TxManager реализует repository.Transactor: транзакция кладётся в контекст,
+// и все репозитории, получившие этот контекст, работают внутри неё.
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx выполняет fn в транзакции. Если в ctx уже есть транзакция,
+// fn выполняется в ней, а фиксацией управляет внешний вызов.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.withinTx(ctx, nil, fn)
+}
+
+// snapshot — транзакция только для чтения, все запросы которой видят один
+// снимок данных.
+var snapshot = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+// readSnapshot выполняет несколько чтений fn на одном снимке данных:
+// в транзакции из ctx, а если её нет — в собственной REPEATABLE READ.
+func readSnapshot(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	return NewTxManager(db).withinTx(ctx, snapshot, fn)
+}
+
+func (m *TxManager) withinTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn возвращает транзакцию из ctx, если она есть, иначе сам пул.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return loggedQuerier{tx}
+	}
+	return loggedQuerier{db}
+}
+
+// orgID — организация текущего запроса. Каждый запрос репозиториев
+// фильтрует по ней все затронутые таблицы.
+func orgID(ctx context.Context) string {
+	return tenant.FromContext(ctx)
+}
+
+// inTx выполняет несколько запросов атомарно: внутри транзакции из ctx,
+// а если её нет — в собственной.
+func inTx(ctx context.Context, db *sql.DB, fn func(q querier) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(loggedQuerier{tx})
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(loggedQuerier{tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loggedQuerier пишет каждый запрос в debug-лог логгера из контекста,
+// так что SQL попадает в лог вместе с request_id и trace_id запроса.
+type loggedQuerier struct {
+	q querier
+}
+
+func (l loggedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := l.q.ExecContext(ctx, query, args...)
+	logQuery(ctx, query, start, err)
+	return res, err
+}
+
+func (l loggedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := l.q.QueryContext(ctx, query, args...)
+	logQuery(ctx, query, start, err)
+	return rows, err
+}
+
+// QueryRowContext откладывает ошибку до Scan, поэтому в лог попадает
+// только сам запрос и время его отправки.
+func (l loggedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := l.q.QueryRowContext(ctx, query, args...)
+	logQuery(ctx, query, start, nil)
+	return row
+}
+
+func logQuery(ctx context.Context, query string, start time.Time, err error) {
+	logger := logging.FromContext(ctx)
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	args := []any{
+		"query", strings.Join(strings.Fields(query), " "),
+		"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		args = append(args, "error", err.Error())
+	}
+	logger.DebugContext(ctx, "sql query", args...)
+}
@@ -17,11 +17,13 @@ func NewUserRepo(db *sql.DB) *UserRepo {
 }
 
 func (r *UserRepo) DeactivateByTeam(ctx context.Context, teamName string) (int64, error) {
-	res, err := r.db.ExecContext(ctx,
+	res, err := conn(ctx, r.db).ExecContext(ctx,
 		`UPDATE users
          SET is_active = false
-         WHERE team_name = $1 AND is_active = true`,
-		teamName,
+         WHERE org_id = $1
+           AND team_id = (SELECT id FROM teams WHERE org_id = $1 AND team_name = $2)
+           AND is_active = true`,
+		orgID(ctx), teamName,
 	)
 	if err != nil {
 		return 0, err
@@ -29,31 +31,28 @@ func (r *UserRepo) DeactivateByTeam(ctx context.Context, teamName string) (int64
 	return res.RowsAffected()
 }
 
-func (r *UserRepo) UpsertUser(u domain.User) error {
-	ctx := context.Background()
-
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO users (user_id, username, team_name, is_active)
-         VALUES ($1, $2, $3, $4)
-         ON CONFLICT (user_id) DO UPDATE
+func (r *UserRepo) UpsertUser(ctx context.Context, u domain.User) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO users (org_id, user_id, username, team_id, is_active)
+         VALUES ($1, $2, $3, (SELECT id FROM teams WHERE org_id = $1 AND team_name = $4), $5)
+         ON CONFLICT (org_id, user_id) DO UPDATE
            SET username = EXCLUDED.username,
-               team_name = EXCLUDED.team_name,
+               team_id = EXCLUDED.team_id,
                is_active = EXCLUDED.is_active`,
-		u.ID, u.Username, u.TeamName, u.IsActive,
+		orgID(ctx), u.ID, u.Username, u.TeamName, u.IsActive,
 	)
 	return err
 }
 
-func (r *UserRepo) GetUser(userID string) (*domain.User, error) {
-	ctx := context.Background()
-
+func (r *UserRepo) GetUser(ctx context.Context, userID string) (*domain.User, error) {
 	var u domain.User
-	err := r.db.QueryRowContext(ctx,
-		`SELECT user_id, username, team_name, is_active
-         FROM users
-         WHERE user_id = $1`,
-		userID,
-	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active, COALESCE(t.id, 0)
+         FROM users u
+         LEFT JOIN teams t ON t.id = u.team_id
+         WHERE u.org_id = $1 AND u.user_id = $2`,
+		orgID(ctx), userID,
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.TeamID)
 	if err == sql.ErrNoRows {
 		return nil, repository.ErrNotFound
 	}
@@ -63,14 +62,36 @@ func (r *UserRepo) GetUser(userID string) (*domain.User, error) {
 	return &u, nil
 }
 
-func (r *UserRepo) SetUserActive(userID string, isActive bool) (*domain.User, error) {
-	ctx := context.Background()
+func (r *UserRepo) GetUsers(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active
+         FROM users u
+         LEFT JOIN teams t ON t.id = u.team_id
+         WHERE u.org_id = $1 AND u.user_id = ANY($2)`,
+		orgID(ctx), userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	res, err := r.db.ExecContext(ctx,
+	res := make([]domain.User, 0, len(userIDs))
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		res = append(res, u)
+	}
+	return res, rows.Err()
+}
+
+func (r *UserRepo) SetUserActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
 		`UPDATE users
-         SET is_active = $2
-         WHERE user_id = $1`,
-		userID, isActive,
+         SET is_active = $3
+         WHERE org_id = $1 AND user_id = $2`,
+		orgID(ctx), userID, isActive,
 	)
 	if err != nil {
 		return nil, err
@@ -80,26 +101,25 @@ func (r *UserRepo) SetUserActive(userID string, isActive bool) (*domain.User, er
 		return nil, repository.ErrNotFound
 	}
 
-	return r.GetUser(userID)
+	return r.GetUser(ctx, userID)
 }
 
-func (r *UserRepo) GetActiveUsersByTeam(teamName string, excludeIDs []string) ([]domain.User, error) {
-	ctx := context.Background()
-
+func (r *UserRepo) GetActiveUsersByTeam(ctx context.Context, teamName string, excludeIDs []string) ([]domain.User, error) {
 	query := `
-        SELECT user_id, username, team_name, is_active
-        FROM users
-        WHERE team_name = $1 AND is_active = true`
-	args := []any{teamName}
+        SELECT u.user_id, u.username, t.team_name, u.is_active
+        FROM users u
+        JOIN teams t ON t.id = u.team_id
+        WHERE u.org_id = $1 AND t.team_name = $2 AND u.is_active = true`
+	args := []any{orgID(ctx), teamName}
 
 	if len(excludeIDs) > 0 {
 
-		query += " AND user_id NOT IN ("
+		query += " AND u.user_id NOT IN ("
 		for i := range excludeIDs {
 			if i > 0 {
 				query += ","
 			}
-			query += "$" + fmt.Sprint(i+2)
+			query += "$" + fmt.Sprint(i+3)
 		}
 		query += ")"
 
@@ -108,7 +128,7 @@ func (r *UserRepo) GetActiveUsersByTeam(teamName string, excludeIDs []string) ([
 		}
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -128,3 +148,70 @@ func (r *UserRepo) GetActiveUsersByTeam(teamName string, excludeIDs []string) ([
 
 	return res, nil
 }
+
+func (r *UserRepo) ListUsers(ctx context.Context, f repository.UserFilter) (repository.UserPage, error) {
+	var page repository.UserPage
+
+	ks := keyset{
+		sort:    string(repository.UserSortByID),
+		expr:    "u.user_id",
+		keyType: "text",
+		idExpr:  "u.user_id",
+		desc:    f.Order == repository.SortDesc,
+	}
+	if f.Sort == repository.UserSortByUsername {
+		ks.sort = string(repository.UserSortByUsername)
+		ks.expr = "u.username"
+	}
+
+	var b whereBuilder
+	b.add("u.org_id = " + b.arg(orgID(ctx)))
+	if f.TeamName != "" {
+		b.add("t.team_name = " + b.arg(f.TeamName))
+	}
+	if f.IsActive != nil {
+		b.add("u.is_active = " + b.arg(*f.IsActive))
+	}
+	orderBy, err := ks.apply(&b, f.Cursor)
+	if err != nil {
+		return page, err
+	}
+	limit := pageLimit(f.Page)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT u.user_id, u.username, COALESCE(t.team_name, ''), u.is_active, `+ks.keyColumn()+`
+         FROM users u
+         LEFT JOIN teams t ON t.id = u.team_id`+
+			b.sql()+
+			orderBy+
+			fmt.Sprintf(" LIMIT %d", limit+1),
+		b.args...,
+	)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Items = make([]domain.User, 0, limit)
+	var lastKey string
+	for rows.Next() {
+		var u domain.User
+		var key string
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &key); err != nil {
+			return page, err
+		}
+		if len(page.Items) < limit {
+			lastKey = key
+		}
+		page.Items = append(page.Items, u)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(ks.sort, lastKey, page.Items[limit-1].ID)
+	}
+	return page, nil
+}
@@ -4,16 +4,58 @@ import (
 	"avito/internal/domain"
 	"context"
 	"errors"
+	"slices"
+	"time"
 )
 
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrAlreadyExists = errors.New("already exists")
+	ErrInvalidCursor = errors.New("invalid cursor")
 )
 
+// StatsBucket — шаг группировки трендов во времени.
+type StatsBucket string
+
+const (
+	StatsBucketDay   StatsBucket = "day"
+	StatsBucketWeek  StatsBucket = "week"
+	StatsBucketMonth StatsBucket = "month"
+)
+
+// StatsFilter ограничивает статистику окном [From, To) по created_at PR
+// (для трендов merge — по merged_at) и командой. Пустые поля не фильтруют.
+type StatsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+	Bucket   StatsBucket
+}
+
+// Stats — статистика по организации; PerReviewer и PerStatus — суммы по Teams.
 type Stats struct {
 	PerReviewer map[string]int64
 	PerStatus   map[string]int64
+	Teams       []TeamStats
+}
+
+// TeamStats — статистика одной команды. Метрики PR группируются по команде
+// автора, метрики ревьюверов — по команде ревьювера. OpenReviewsPerUser и
+// InactiveWithReviews отражают текущее состояние и не зависят от окна.
+type TeamStats struct {
+	TeamName            string
+	PerReviewer         map[string]int64
+	PerStatus           map[string]int64
+	OpenReviewsPerUser  map[string]int64
+	InactiveWithReviews map[string]int64
+	Trend               []TrendPoint
+}
+
+// TrendPoint — число созданных и смёрженных PR за один период.
+type TrendPoint struct {
+	Period  time.Time
+	Created int64
+	Merged  int64
 }
 
 type ReviewerAssignment struct {
@@ -21,24 +63,270 @@ type ReviewerAssignment struct {
 	UserID string
 }
 
+// Transactor выполняет fn в одной транзакции. Репозитории, получившие
+// переданный в fn контекст, работают внутри этой транзакции.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type TeamRepository interface {
-	CreateTeam(team domain.Team) error
-	GetTeam(name string) (*domain.Team, error)
+	CreateTeam(ctx context.Context, team domain.Team) error
+	GetTeam(ctx context.Context, name string) (*domain.Team, error)
+	// GetTeams возвращает команды с участниками одним запросом;
+	// несуществующие имена пропускаются.
+	GetTeams(ctx context.Context, names []string) ([]domain.Team, error)
+	AddMembers(ctx context.Context, teamName string, members []domain.TeamMember) error
+	RemoveMembers(ctx context.Context, teamName string, userIDs []string) (int64, error)
+	MoveMember(ctx context.Context, userID, toTeamName string) error
+	MoveAllMembers(ctx context.Context, fromTeamName, toTeamName string) (int64, error)
+	RenameTeam(ctx context.Context, oldName, newName string) error
+	DeleteTeam(ctx context.Context, name string) error
+	ListTeams(ctx context.Context, f TeamFilter) (TeamPage, error)
 }
 
 type UserRepository interface {
-	UpsertUser(u domain.User) error
-	GetUser(userID string) (*domain.User, error)
-	SetUserActive(userID string, isActive bool) (*domain.User, error)
-	GetActiveUsersByTeam(teamName string, excludeIDs []string) ([]domain.User, error)
+	UpsertUser(ctx context.Context, u domain.User) error
+	GetUser(ctx context.Context, userID string) (*domain.User, error)
+	// GetUsers возвращает пользователей по списку ID; несуществующие пропускаются.
+	GetUsers(ctx context.Context, userIDs []string) ([]domain.User, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
+	GetActiveUsersByTeam(ctx context.Context, teamName string, excludeIDs []string) ([]domain.User, error)
 	DeactivateByTeam(ctx context.Context, teamName string) (int64, error)
+	ListUsers(ctx context.Context, f UserFilter) (UserPage, error)
 }
 
 type PullRequestRepository interface {
-	CreatePR(pr domain.PullRequest) error
-	GetPR(id string) (*domain.PullRequest, error)
-	UpdatePR(pr domain.PullRequest) error
-	GetPRsByReviewer(userID string) ([]domain.PullRequest, error)
+	CreatePR(ctx context.Context, pr domain.PullRequest) error
+	GetPR(ctx context.Context, id string) (*domain.PullRequest, error)
+	// GetPRForUpdate — GetPR, блокирующий PR до конца транзакции из ctx;
+	// чтение и запись PR при переназначении идут в одной транзакции.
+	GetPRForUpdate(ctx context.Context, id string) (*domain.PullRequest, error)
+	// GetPRs возвращает PR с ревьюверами по списку ID; несуществующие пропускаются.
+	GetPRs(ctx context.Context, ids []string) ([]domain.PullRequest, error)
+	UpdatePR(ctx context.Context, pr domain.PullRequest) error
+	GetPRsByReviewer(ctx context.Context, userID string, f ReviewFilter) (ReviewPage, error)
+	GetOpenPRsByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error)
+	// GetOpenPRsByReviewers — GetOpenPRsByReviewer для нескольких ревьюверов
+	// сразу; ключ — user_id ревьювера.
+	GetOpenPRsByReviewers(ctx context.Context, userIDs []string) (map[string][]domain.PullRequest, error)
 	GetOpenAssignmentsByTeam(ctx context.Context, teamName string) ([]ReviewerAssignment, error)
-	GetStats(ctx context.Context) (Stats, error)
+	GetStats(ctx context.Context, f StatsFilter) (Stats, error)
+	GetMergeLatency(ctx context.Context, f LatencyFilter) (MergeLatency, error)
+	GetReviewLoad(ctx context.Context, teamName string, from, to time.Time) ([]MemberLoad, error)
+	RecordAssignment(ctx context.Context, d domain.AssignmentDecision) error
+	GetAssignmentDecisions(ctx context.Context, prID string) ([]domain.AssignmentDecision, error)
+	ListPRs(ctx context.Context, f PRFilter) (PRPage, error)
+}
+
+// OrgRepository работает с организациями. В отличие от остальных
+// репозиториев не фильтрует по организации из контекста.
+type OrgRepository interface {
+	// CreateOrg возвращает ErrAlreadyExists, если id занят.
+	CreateOrg(ctx context.Context, org domain.Organization) (*domain.Organization, error)
+	GetOrg(ctx context.Context, id string) (*domain.Organization, error)
+	ListOrgs(ctx context.Context) ([]domain.Organization, error)
+}
+
+type APIKeyRepository interface {
+	// CreateAPIKey сохраняет ключ с хэшем секрета; ErrNotFound, если
+	// команда или пользователь, к которым он привязан, не существуют.
+	CreateAPIKey(ctx context.Context, key domain.APIKey, hash []byte) (*domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash []byte) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error)
+	// RevokeTeamKeys отзывает ключи team-lead команды и отвязывает их от
+	// неё, чтобы команду можно было удалить; возвращает число ключей.
+	RevokeTeamKeys(ctx context.Context, teamName string) (int64, error)
+	// TouchAPIKey обновляет last_used_at не чаще раза в минуту.
+	TouchAPIKey(ctx context.Context, id int64) error
+}
+
+// EventRepository — журнал событий PR организации из контекста.
+type EventRepository interface {
+	// AppendEvent записывает событие и возвращает его с ID и временем записи.
+	AppendEvent(ctx context.Context, e domain.Event) (*domain.Event, error)
+	GetEvent(ctx context.Context, id int64) (*domain.Event, error)
+	// ListEvents возвращает до limit событий с ID больше afterID по
+	// возрастанию ID.
+	ListEvents(ctx context.Context, f EventFilter, afterID int64, limit int) ([]domain.Event, error)
+	// PruneEvents удаляет события всех организаций, записанные раньше before.
+	PruneEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SortOrder — направление сортировки в списочных запросах.
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// Page — параметры курсорной пагинации. Cursor непрозрачен для клиента
+// и действителен только для той же сортировки, с которой был выдан.
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+type TeamFilter struct {
+	Order SortOrder
+	Page
+}
+
+// TeamSummary — краткое описание команды для списков.
+type TeamSummary struct {
+	TeamName      string
+	Members       int64
+	ActiveMembers int64
+}
+
+type TeamPage struct {
+	Items      []TeamSummary
+	NextCursor string
+}
+
+type UserSortField string
+
+const (
+	UserSortByID       UserSortField = "user_id"
+	UserSortByUsername UserSortField = "username"
+)
+
+type UserFilter struct {
+	TeamName string
+	IsActive *bool
+	Sort     UserSortField
+	Order    SortOrder
+	Page
+}
+
+type UserPage struct {
+	Items      []domain.User
+	NextCursor string
+}
+
+type PRSortField string
+
+const (
+	PRSortByID        PRSortField = "pull_request_id"
+	PRSortByCreatedAt PRSortField = "created_at"
+	PRSortByMergedAt  PRSortField = "merged_at"
+)
+
+// PRFilter задаёт выборку PR; пустые поля не фильтруют.
+// TeamName фильтрует по команде автора.
+type PRFilter struct {
+	Status      domain.PullRequestStatus
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	Sort        PRSortField
+	Order       SortOrder
+	Page
+}
+
+type PRPage struct {
+	Items      []domain.PullRequest
+	NextCursor string
+}
+
+// ReviewFilter задаёт выборку PR, назначенных ревьюверу. Since/Until
+// ограничивают поле сортировки: created_at или merged_at.
+type ReviewFilter struct {
+	Status domain.PullRequestStatus
+	Since  *time.Time
+	Until  *time.Time
+	Sort   PRSortField
+	Order  SortOrder
+	Page
+}
+
+// ReviewPage — страница ревью с общими счётчиками по всей выборке.
+// TotalByStatus считается без учёта фильтра по статусу.
+type ReviewPage struct {
+	Items         []domain.PullRequest
+	NextCursor    string
+	Total         int64
+	TotalByStatus map[domain.PullRequestStatus]int64
+}
+
+// LatencyFilter ограничивает выборку PR, смёрженных в окне [From, To).
+type LatencyFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+}
+
+// LatencyPercentiles — перцентили длительности в секундах по Count PR.
+type LatencyPercentiles struct {
+	Count int64
+	P50   float64
+	P90   float64
+	P99   float64
+}
+
+type TeamLatency struct {
+	TeamName string
+	LatencyPercentiles
+}
+
+type AuthorLatency struct {
+	AuthorID string
+	TeamName string
+	LatencyPercentiles
+}
+
+// MergeLatency — время от создания до merge PR по командам и авторам.
+type MergeLatency struct {
+	PerTeam   []TeamLatency
+	PerAuthor []AuthorLatency
+}
+
+// MemberLoad — нагрузка участника команды за окно: число назначений
+// на PR, созданные в окне, и число дней, когда он был активен.
+type MemberLoad struct {
+	UserID      string
+	IsActive    bool
+	Assignments int64
+	DaysActive  float64
+}
+
+// OpenCounts — число открытых PR (по команде автора) и открытых
+// назначений ревьюверов (по команде ревьювера).
+type OpenCounts struct {
+	PullRequests int64
+	Reviews      int64
+}
+
+// OrgTeam — команда в пределах организации; ключ сводок по всем организациям.
+type OrgTeam struct {
+	OrgID    string
+	TeamName string
+}
+
+// EventFilter отбирает события потока; пустые поля не фильтруют.
+// UserID совпадает с автором, ревьювером или снятым ревьювером,
+// TeamName — с командой автора.
+type EventFilter struct {
+	UserID   string
+	TeamName string
+	PRID     string
+}
+
+// Match проверяет событие тем же условием, что ListEvents в SQL.
+func (f EventFilter) Match(e domain.Event) bool {
+	if f.PRID != "" && e.PullRequest.ID != f.PRID {
+		return false
+	}
+	if f.TeamName != "" && e.TeamName != f.TeamName {
+		return false
+	}
+	if f.UserID != "" && !slices.Contains(e.UserIDs(), f.UserID) {
+		return false
+	}
+	return true
 }
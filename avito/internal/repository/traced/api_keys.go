@@ -0,0 +1,71 @@
+package traced
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type APIKeyRepo struct {
+	next repository.APIKeyRepository
+}
+
+func NewAPIKeyRepo(next repository.APIKeyRepository) *APIKeyRepo {
+	return &APIKeyRepo{next: next}
+}
+
+func keyID(id int64) attribute.KeyValue {
+	return attribute.Int64("api_key.id", id)
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key domain.APIKey, hash []byte) (_ *domain.APIKey, err error) {
+	ctx, span := start(ctx, "APIKeyRepo.CreateAPIKey", attribute.String("api_key.role", string(key.Role)))
+	defer func() { end(span, err) }()
+
+	return r.next.CreateAPIKey(ctx, key, hash)
+}
+
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash []byte) (_ *domain.APIKey, err error) {
+	ctx, span := start(ctx, "APIKeyRepo.GetAPIKeyByHash")
+	defer func() { end(span, err) }()
+
+	key, err := r.next.GetAPIKeyByHash(ctx, hash)
+	if err == nil {
+		span.SetAttributes(keyID(key.ID))
+	}
+	return key, err
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context) (_ []domain.APIKey, err error) {
+	ctx, span := start(ctx, "APIKeyRepo.ListAPIKeys")
+	defer func() { end(span, err) }()
+
+	keys, err := r.next.ListAPIKeys(ctx)
+	span.SetAttributes(rows(len(keys)))
+	return keys, err
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, id int64) (_ *domain.APIKey, err error) {
+	ctx, span := start(ctx, "APIKeyRepo.RevokeAPIKey", keyID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.RevokeAPIKey(ctx, id)
+}
+
+func (r *APIKeyRepo) RevokeTeamKeys(ctx context.Context, name string) (_ int64, err error) {
+	ctx, span := start(ctx, "APIKeyRepo.RevokeTeamKeys", teamName(name))
+	defer func() { end(span, err) }()
+
+	n, err := r.next.RevokeTeamKeys(ctx, name)
+	span.SetAttributes(affected(n))
+	return n, err
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int64) (err error) {
+	ctx, span := start(ctx, "APIKeyRepo.TouchAPIKey", keyID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.TouchAPIKey(ctx, id)
+}
@@ -0,0 +1,60 @@
+package traced
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type EventRepo struct {
+	next repository.EventRepository
+}
+
+func NewEventRepo(next repository.EventRepository) *EventRepo {
+	return &EventRepo{next: next}
+}
+
+func eventID(id int64) attribute.KeyValue {
+	return attribute.Int64("event.id", id)
+}
+
+func (r *EventRepo) AppendEvent(ctx context.Context, e domain.Event) (_ *domain.Event, err error) {
+	ctx, span := start(ctx, "EventRepo.AppendEvent", prID(e.PullRequest.ID),
+		attribute.String("event.type", string(e.Type)))
+	defer func() { end(span, err) }()
+
+	created, err := r.next.AppendEvent(ctx, e)
+	if created != nil {
+		span.SetAttributes(eventID(created.ID))
+	}
+	return created, err
+}
+
+func (r *EventRepo) GetEvent(ctx context.Context, id int64) (_ *domain.Event, err error) {
+	ctx, span := start(ctx, "EventRepo.GetEvent", eventID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.GetEvent(ctx, id)
+}
+
+func (r *EventRepo) ListEvents(ctx context.Context, f repository.EventFilter, afterID int64, limit int) (_ []domain.Event, err error) {
+	ctx, span := start(ctx, "EventRepo.ListEvents", eventID(afterID),
+		userID(f.UserID), teamName(f.TeamName), prID(f.PRID))
+	defer func() { end(span, err) }()
+
+	events, err := r.next.ListEvents(ctx, f, afterID, limit)
+	span.SetAttributes(rows(len(events)))
+	return events, err
+}
+
+func (r *EventRepo) PruneEvents(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := start(ctx, "EventRepo.PruneEvents", attribute.String("events.before", before.Format(time.RFC3339)))
+	defer func() { end(span, err) }()
+
+	n, err := r.next.PruneEvents(ctx, before)
+	span.SetAttributes(affected(n))
+	return n, err
+}
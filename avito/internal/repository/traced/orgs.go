@@ -0,0 +1,44 @@
+package traced
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type OrgRepo struct {
+	next repository.OrgRepository
+}
+
+func NewOrgRepo(next repository.OrgRepository) *OrgRepo {
+	return &OrgRepo{next: next}
+}
+
+func orgID(id string) attribute.KeyValue {
+	return attribute.String("org.id", id)
+}
+
+func (r *OrgRepo) CreateOrg(ctx context.Context, org domain.Organization) (_ *domain.Organization, err error) {
+	ctx, span := start(ctx, "OrgRepo.CreateOrg", orgID(org.ID))
+	defer func() { end(span, err) }()
+
+	return r.next.CreateOrg(ctx, org)
+}
+
+func (r *OrgRepo) GetOrg(ctx context.Context, id string) (_ *domain.Organization, err error) {
+	ctx, span := start(ctx, "OrgRepo.GetOrg", orgID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.GetOrg(ctx, id)
+}
+
+func (r *OrgRepo) ListOrgs(ctx context.Context) (_ []domain.Organization, err error) {
+	ctx, span := start(ctx, "OrgRepo.ListOrgs")
+	defer func() { end(span, err) }()
+
+	orgs, err := r.next.ListOrgs(ctx)
+	span.SetAttributes(rows(len(orgs)))
+	return orgs, err
+}
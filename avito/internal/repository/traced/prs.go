@@ -0,0 +1,152 @@
+package traced
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type PRRepo struct {
+	next repository.PullRequestRepository
+}
+
+func NewPRRepo(next repository.PullRequestRepository) *PRRepo {
+	return &PRRepo{next: next}
+}
+
+func (r *PRRepo) CreatePR(ctx context.Context, pr domain.PullRequest) (err error) {
+	ctx, span := start(ctx, "PRRepo.CreatePR", prID(pr.ID), userID(pr.AuthorID),
+		attribute.StringSlice("pr.reviewers", pr.AssignedReviewers))
+	defer func() { end(span, err) }()
+
+	return r.next.CreatePR(ctx, pr)
+}
+
+func (r *PRRepo) GetPR(ctx context.Context, id string) (_ *domain.PullRequest, err error) {
+	ctx, span := start(ctx, "PRRepo.GetPR", prID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.GetPR(ctx, id)
+}
+
+func (r *PRRepo) GetPRForUpdate(ctx context.Context, id string) (_ *domain.PullRequest, err error) {
+	ctx, span := start(ctx, "PRRepo.GetPRForUpdate", prID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.GetPRForUpdate(ctx, id)
+}
+
+func (r *PRRepo) GetPRs(ctx context.Context, ids []string) (_ []domain.PullRequest, err error) {
+	ctx, span := start(ctx, "PRRepo.GetPRs", attribute.StringSlice("pr.ids", ids))
+	defer func() { end(span, err) }()
+
+	prs, err := r.next.GetPRs(ctx, ids)
+	span.SetAttributes(rows(len(prs)))
+	return prs, err
+}
+
+func (r *PRRepo) UpdatePR(ctx context.Context, pr domain.PullRequest) (err error) {
+	ctx, span := start(ctx, "PRRepo.UpdatePR", prID(pr.ID),
+		attribute.String("pr.status", string(pr.Status)),
+		attribute.StringSlice("pr.reviewers", pr.AssignedReviewers))
+	defer func() { end(span, err) }()
+
+	return r.next.UpdatePR(ctx, pr)
+}
+
+func (r *PRRepo) GetPRsByReviewer(ctx context.Context, id string, f repository.ReviewFilter) (_ repository.ReviewPage, err error) {
+	ctx, span := start(ctx, "PRRepo.GetPRsByReviewer", userID(id))
+	defer func() { end(span, err) }()
+
+	page, err := r.next.GetPRsByReviewer(ctx, id, f)
+	span.SetAttributes(rows(len(page.Items)), attribute.Int64("pr.total", page.Total))
+	return page, err
+}
+
+func (r *PRRepo) GetOpenPRsByReviewers(ctx context.Context, ids []string) (_ map[string][]domain.PullRequest, err error) {
+	ctx, span := start(ctx, "PRRepo.GetOpenPRsByReviewers", attribute.StringSlice("user.ids", ids))
+	defer func() { end(span, err) }()
+
+	res, err := r.next.GetOpenPRsByReviewers(ctx, ids)
+	n := 0
+	for _, prs := range res {
+		n += len(prs)
+	}
+	span.SetAttributes(rows(n))
+	return res, err
+}
+
+func (r *PRRepo) GetOpenPRsByReviewer(ctx context.Context, id string) (_ []domain.PullRequest, err error) {
+	ctx, span := start(ctx, "PRRepo.GetOpenPRsByReviewer", userID(id))
+	defer func() { end(span, err) }()
+
+	prs, err := r.next.GetOpenPRsByReviewer(ctx, id)
+	span.SetAttributes(rows(len(prs)))
+	return prs, err
+}
+
+func (r *PRRepo) GetOpenAssignmentsByTeam(ctx context.Context, name string) (_ []repository.ReviewerAssignment, err error) {
+	ctx, span := start(ctx, "PRRepo.GetOpenAssignmentsByTeam", teamName(name))
+	defer func() { end(span, err) }()
+
+	assignments, err := r.next.GetOpenAssignmentsByTeam(ctx, name)
+	span.SetAttributes(rows(len(assignments)))
+	return assignments, err
+}
+
+func (r *PRRepo) GetStats(ctx context.Context, f repository.StatsFilter) (_ repository.Stats, err error) {
+	ctx, span := start(ctx, "PRRepo.GetStats", teamName(f.TeamName))
+	defer func() { end(span, err) }()
+
+	stats, err := r.next.GetStats(ctx, f)
+	span.SetAttributes(rows(len(stats.Teams)))
+	return stats, err
+}
+
+func (r *PRRepo) GetMergeLatency(ctx context.Context, f repository.LatencyFilter) (_ repository.MergeLatency, err error) {
+	ctx, span := start(ctx, "PRRepo.GetMergeLatency")
+	defer func() { end(span, err) }()
+
+	latency, err := r.next.GetMergeLatency(ctx, f)
+	span.SetAttributes(rows(len(latency.PerTeam) + len(latency.PerAuthor)))
+	return latency, err
+}
+
+func (r *PRRepo) GetReviewLoad(ctx context.Context, name string, from, to time.Time) (_ []repository.MemberLoad, err error) {
+	ctx, span := start(ctx, "PRRepo.GetReviewLoad", teamName(name))
+	defer func() { end(span, err) }()
+
+	loads, err := r.next.GetReviewLoad(ctx, name, from, to)
+	span.SetAttributes(rows(len(loads)))
+	return loads, err
+}
+
+func (r *PRRepo) RecordAssignment(ctx context.Context, d domain.AssignmentDecision) (err error) {
+	ctx, span := start(ctx, "PRRepo.RecordAssignment", prID(d.PRID),
+		attribute.String("assignment.action", string(d.Action)),
+		attribute.String("assignment.strategy", string(d.Strategy)))
+	defer func() { end(span, err) }()
+
+	return r.next.RecordAssignment(ctx, d)
+}
+
+func (r *PRRepo) GetAssignmentDecisions(ctx context.Context, id string) (_ []domain.AssignmentDecision, err error) {
+	ctx, span := start(ctx, "PRRepo.GetAssignmentDecisions", prID(id))
+	defer func() { end(span, err) }()
+
+	decisions, err := r.next.GetAssignmentDecisions(ctx, id)
+	span.SetAttributes(rows(len(decisions)))
+	return decisions, err
+}
+
+func (r *PRRepo) ListPRs(ctx context.Context, f repository.PRFilter) (_ repository.PRPage, err error) {
+	ctx, span := start(ctx, "PRRepo.ListPRs", teamName(f.TeamName))
+	defer func() { end(span, err) }()
+
+	page, err := r.next.ListPRs(ctx, f)
+	span.SetAttributes(rows(len(page.Items)))
+	return page, err
+}
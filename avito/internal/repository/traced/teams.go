@@ -0,0 +1,100 @@
+package traced
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type TeamRepo struct {
+	next repository.TeamRepository
+}
+
+func NewTeamRepo(next repository.TeamRepository) *TeamRepo {
+	return &TeamRepo{next: next}
+}
+
+func (r *TeamRepo) CreateTeam(ctx context.Context, team domain.Team) (err error) {
+	ctx, span := start(ctx, "TeamRepo.CreateTeam", teamName(team.TeamName), rows(len(team.Members)))
+	defer func() { end(span, err) }()
+
+	return r.next.CreateTeam(ctx, team)
+}
+
+func (r *TeamRepo) GetTeam(ctx context.Context, name string) (_ *domain.Team, err error) {
+	ctx, span := start(ctx, "TeamRepo.GetTeam", teamName(name))
+	defer func() { end(span, err) }()
+
+	team, err := r.next.GetTeam(ctx, name)
+	if err == nil {
+		span.SetAttributes(rows(len(team.Members)))
+	}
+	return team, err
+}
+
+func (r *TeamRepo) GetTeams(ctx context.Context, names []string) (_ []domain.Team, err error) {
+	ctx, span := start(ctx, "TeamRepo.GetTeams", attribute.StringSlice("team.names", names))
+	defer func() { end(span, err) }()
+
+	teams, err := r.next.GetTeams(ctx, names)
+	span.SetAttributes(rows(len(teams)))
+	return teams, err
+}
+
+func (r *TeamRepo) AddMembers(ctx context.Context, name string, members []domain.TeamMember) (err error) {
+	ctx, span := start(ctx, "TeamRepo.AddMembers", teamName(name), rows(len(members)))
+	defer func() { end(span, err) }()
+
+	return r.next.AddMembers(ctx, name, members)
+}
+
+func (r *TeamRepo) RemoveMembers(ctx context.Context, name string, userIDs []string) (_ int64, err error) {
+	ctx, span := start(ctx, "TeamRepo.RemoveMembers", teamName(name), attribute.StringSlice("user.ids", userIDs))
+	defer func() { end(span, err) }()
+
+	n, err := r.next.RemoveMembers(ctx, name, userIDs)
+	span.SetAttributes(affected(n))
+	return n, err
+}
+
+func (r *TeamRepo) MoveMember(ctx context.Context, id, toTeamName string) (err error) {
+	ctx, span := start(ctx, "TeamRepo.MoveMember", userID(id), teamName(toTeamName))
+	defer func() { end(span, err) }()
+
+	return r.next.MoveMember(ctx, id, toTeamName)
+}
+
+func (r *TeamRepo) MoveAllMembers(ctx context.Context, fromTeamName, toTeamName string) (_ int64, err error) {
+	ctx, span := start(ctx, "TeamRepo.MoveAllMembers",
+		teamName(fromTeamName), attribute.String("team.to_name", toTeamName))
+	defer func() { end(span, err) }()
+
+	n, err := r.next.MoveAllMembers(ctx, fromTeamName, toTeamName)
+	span.SetAttributes(affected(n))
+	return n, err
+}
+
+func (r *TeamRepo) RenameTeam(ctx context.Context, oldName, newName string) (err error) {
+	ctx, span := start(ctx, "TeamRepo.RenameTeam", teamName(oldName), attribute.String("team.new_name", newName))
+	defer func() { end(span, err) }()
+
+	return r.next.RenameTeam(ctx, oldName, newName)
+}
+
+func (r *TeamRepo) DeleteTeam(ctx context.Context, name string) (err error) {
+	ctx, span := start(ctx, "TeamRepo.DeleteTeam", teamName(name))
+	defer func() { end(span, err) }()
+
+	return r.next.DeleteTeam(ctx, name)
+}
+
+func (r *TeamRepo) ListTeams(ctx context.Context, f repository.TeamFilter) (_ repository.TeamPage, err error) {
+	ctx, span := start(ctx, "TeamRepo.ListTeams")
+	defer func() { end(span, err) }()
+
+	page, err := r.next.ListTeams(ctx, f)
+	span.SetAttributes(rows(len(page.Items)))
+	return page, err
+}
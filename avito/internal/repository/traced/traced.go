@@ -0,0 +1,69 @@
+// Package traced оборачивает репозитории спанами OpenTelemetry, не трогая
+// SQL-реализацию: каждый вызов получает спан с идентификаторами из аргументов
+// и числом затронутых или возвращённых строк.
+package traced
+
+import (
+	"avito/internal/repository"
+	"avito/internal/tracing"
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "postgresql"))
+	return tracing.Start(ctx, name, attrs...)
+}
+
+// end закрывает спан. ErrNotFound и ErrAlreadyExists — штатные исходы,
+// которые сервисный слой превращает в доменные ошибки, поэтому спан
+// репозитория ими не помечается как ошибочный.
+func end(span trace.Span, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		span.SetAttributes(attribute.String("db.result", "not_found"))
+		err = nil
+	case errors.Is(err, repository.ErrAlreadyExists):
+		span.SetAttributes(attribute.String("db.result", "already_exists"))
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+func rows(n int) attribute.KeyValue {
+	return attribute.Int("db.rows", n)
+}
+
+func affected(n int64) attribute.KeyValue {
+	return attribute.Int64("db.rows_affected", n)
+}
+
+func teamName(name string) attribute.KeyValue {
+	return attribute.String("team.name", name)
+}
+
+func userID(id string) attribute.KeyValue {
+	return attribute.String("user.id", id)
+}
+
+func prID(id string) attribute.KeyValue {
+	return attribute.String("pr.id", id)
+}
+
+type Transactor struct {
+	next repository.Transactor
+}
+
+func NewTransactor(next repository.Transactor) *Transactor {
+	return &Transactor{next: next}
+}
+
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, span := start(ctx, "Transactor.WithinTx")
+	defer func() { end(span, err) }()
+
+	return t.next.WithinTx(ctx, fn)
+}
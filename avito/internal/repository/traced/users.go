@@ -0,0 +1,75 @@
+package traced
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type UserRepo struct {
+	next repository.UserRepository
+}
+
+func NewUserRepo(next repository.UserRepository) *UserRepo {
+	return &UserRepo{next: next}
+}
+
+func (r *UserRepo) UpsertUser(ctx context.Context, u domain.User) (err error) {
+	ctx, span := start(ctx, "UserRepo.UpsertUser", userID(u.ID), teamName(u.TeamName))
+	defer func() { end(span, err) }()
+
+	return r.next.UpsertUser(ctx, u)
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id string) (_ *domain.User, err error) {
+	ctx, span := start(ctx, "UserRepo.GetUser", userID(id))
+	defer func() { end(span, err) }()
+
+	return r.next.GetUser(ctx, id)
+}
+
+func (r *UserRepo) GetUsers(ctx context.Context, ids []string) (_ []domain.User, err error) {
+	ctx, span := start(ctx, "UserRepo.GetUsers", attribute.StringSlice("user.ids", ids))
+	defer func() { end(span, err) }()
+
+	users, err := r.next.GetUsers(ctx, ids)
+	span.SetAttributes(rows(len(users)))
+	return users, err
+}
+
+func (r *UserRepo) SetUserActive(ctx context.Context, id string, isActive bool) (_ *domain.User, err error) {
+	ctx, span := start(ctx, "UserRepo.SetUserActive", userID(id), attribute.Bool("user.is_active", isActive))
+	defer func() { end(span, err) }()
+
+	return r.next.SetUserActive(ctx, id, isActive)
+}
+
+func (r *UserRepo) GetActiveUsersByTeam(ctx context.Context, name string, excludeIDs []string) (_ []domain.User, err error) {
+	ctx, span := start(ctx, "UserRepo.GetActiveUsersByTeam",
+		teamName(name), attribute.StringSlice("user.exclude_ids", excludeIDs))
+	defer func() { end(span, err) }()
+
+	users, err := r.next.GetActiveUsersByTeam(ctx, name, excludeIDs)
+	span.SetAttributes(rows(len(users)))
+	return users, err
+}
+
+func (r *UserRepo) DeactivateByTeam(ctx context.Context, name string) (_ int64, err error) {
+	ctx, span := start(ctx, "UserRepo.DeactivateByTeam", teamName(name))
+	defer func() { end(span, err) }()
+
+	n, err := r.next.DeactivateByTeam(ctx, name)
+	span.SetAttributes(affected(n))
+	return n, err
+}
+
+func (r *UserRepo) ListUsers(ctx context.Context, f repository.UserFilter) (_ repository.UserPage, err error) {
+	ctx, span := start(ctx, "UserRepo.ListUsers", teamName(f.TeamName))
+	defer func() { end(span, err) }()
+
+	page, err := r.next.ListUsers(ctx, f)
+	span.SetAttributes(rows(len(page.Items)))
+	return page, err
+}
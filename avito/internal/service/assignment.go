@@ -0,0 +1,96 @@
+package service
+
+import (
+	"avito/internal/domain"
+	"avito/internal/repository"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PullRequestOption настраивает PullRequestService при создании.
+type PullRequestOption func(*PullRequestService)
+
+// WithRandSource задаёт источник случайности, из которого берутся seed'ы
+// для стратегии AssignmentRandom. Удобно для воспроизводимых тестов.
+func WithRandSource(src rand.Source) PullRequestOption {
+	return func(s *PullRequestService) {
+		s.picker.rnd = rand.New(src)
+	}
+}
+
+// WithDeterministicAssignment включает стратегию AssignmentPRHash:
+// seed вычисляется из ID PR, и один и тот же набор кандидатов
+// всегда даёт один и тот же выбор.
+func WithDeterministicAssignment() PullRequestOption {
+	return func(s *PullRequestService) {
+		s.picker.strategy = domain.AssignmentPRHash
+	}
+}
+
+// WithReviewerCount задаёт, сколько ревьюверов назначается на новый PR
+// (по умолчанию DefaultReviewerCount).
+func WithReviewerCount(n int) PullRequestOption {
+	return func(s *PullRequestService) {
+		s.reviewerCount = n
+	}
+}
+
+// WithEventLog включает запись событий PR (создание, переназначение, merge)
+// в журнал events. Событие пишется в одной транзакции с изменением PR.
+func WithEventLog(events repository.EventRepository) PullRequestOption {
+	return func(s *PullRequestService) {
+		s.events = events
+	}
+}
+
+// reviewerPicker выдаёт seed для очередного решения о назначении.
+type reviewerPicker struct {
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	strategy domain.AssignmentStrategy
+}
+
+func newReviewerPicker() *reviewerPicker {
+	return &reviewerPicker{
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		strategy: domain.AssignmentRandom,
+	}
+}
+
+func (p *reviewerPicker) seed(prID string) (domain.AssignmentStrategy, int64) {
+	if p.strategy == domain.AssignmentPRHash {
+		return p.strategy, prIDSeed(prID)
+	}
+
+	// *rand.Rand не потокобезопасен
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.strategy, p.rnd.Int63()
+}
+
+func prIDSeed(prID string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(prID))
+	return int64(h.Sum64())
+}
+
+// PickReviewers детерминированно выбирает до n кандидатов по seed.
+// Кандидаты предварительно сортируются, поэтому результат не зависит
+// от порядка, в котором их вернула БД.
+func PickReviewers(candidates []string, n int, seed int64) []string {
+	pool := slices.Clone(candidates)
+	slices.Sort(pool)
+
+	rnd := rand.New(rand.NewSource(seed))
+	rnd.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+
+	if len(pool) > n {
+		pool = pool[:n]
+	}
+	return pool
+}
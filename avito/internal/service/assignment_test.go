@@ -0,0 +1,235 @@
+package service
+
+import (
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/repository"
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+type txKey struct{}
+
+// fakeTx помечает контекст fn и запоминает, чем закончилась транзакция.
+type fakeTx struct {
+	committed, rolledBack int
+}
+
+func (t *fakeTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		t.rolledBack++
+		return err
+	}
+	t.committed++
+	return nil
+}
+
+func inTx(ctx context.Context) bool {
+	v, _ := ctx.Value(txKey{}).(bool)
+	return v
+}
+
+// assignmentRepos — минимальные репозитории для Create: автор u1 в команде
+// backend из пяти активных участников.
+type assignmentRepos struct {
+	repository.PullRequestRepository
+	repository.UserRepository
+	repository.TeamRepository
+
+	recordErr   error
+	created     []domain.PullRequest
+	updated     []domain.PullRequest
+	decisions   []domain.AssignmentDecision
+	writesOutTx int
+	// stored — PR, который отдаёт GetPRForUpdate; readsOutTx считает его
+	// чтения вне транзакции.
+	stored     *domain.PullRequest
+	readsOutTx int
+}
+
+func (r *assignmentRepos) GetPR(context.Context, string) (*domain.PullRequest, error) {
+	return nil, repository.ErrNotFound
+}
+
+func (r *assignmentRepos) GetPRForUpdate(ctx context.Context, _ string) (*domain.PullRequest, error) {
+	if !inTx(ctx) {
+		r.readsOutTx++
+	}
+	if r.stored == nil {
+		return nil, repository.ErrNotFound
+	}
+	pr := *r.stored
+	pr.AssignedReviewers = slices.Clone(r.stored.AssignedReviewers)
+	return &pr, nil
+}
+
+func (r *assignmentRepos) UpdatePR(ctx context.Context, pr domain.PullRequest) error {
+	if !inTx(ctx) {
+		r.writesOutTx++
+	}
+	r.updated = append(r.updated, pr)
+	return nil
+}
+
+func (r *assignmentRepos) CreatePR(ctx context.Context, pr domain.PullRequest) error {
+	if !inTx(ctx) {
+		r.writesOutTx++
+	}
+	r.created = append(r.created, pr)
+	return nil
+}
+
+func (r *assignmentRepos) RecordAssignment(ctx context.Context, d domain.AssignmentDecision) error {
+	if !inTx(ctx) {
+		r.writesOutTx++
+	}
+	if r.recordErr != nil {
+		return r.recordErr
+	}
+	r.decisions = append(r.decisions, d)
+	return nil
+}
+
+func (r *assignmentRepos) GetUser(_ context.Context, id string) (*domain.User, error) {
+	return &domain.User{ID: id, TeamName: "backend", IsActive: true}, nil
+}
+
+func (r *assignmentRepos) GetTeam(context.Context, string) (*domain.Team, error) {
+	team := &domain.Team{TeamName: "backend"}
+	for _, id := range []string{"u5", "u3", "u1", "u4", "u2"} {
+		team.Members = append(team.Members, domain.TeamMember{UserID: id, IsActive: true})
+	}
+	return team, nil
+}
+
+func (r *assignmentRepos) service(tx repository.Transactor, opts ...PullRequestOption) *PullRequestService {
+	return NewPullRequestService(r, r, r, tx, opts...)
+}
+
+func TestPickReviewersIsReproducible(t *testing.T) {
+	candidates := []string{"u2", "u3", "u4", "u5"}
+	want := PickReviewers(candidates, 2, 42)
+
+	if len(want) != 2 {
+		t.Fatalf("PickReviewers returned %v, want 2 reviewers", want)
+	}
+	for range 10 {
+		if got := PickReviewers(candidates, 2, 42); !slices.Equal(got, want) {
+			t.Fatalf("same seed gave %v, then %v", want, got)
+		}
+	}
+	// порядок кандидатов из БД на выбор не влияет
+	if got := PickReviewers([]string{"u5", "u4", "u3", "u2"}, 2, 42); !slices.Equal(got, want) {
+		t.Fatalf("reordered candidates gave %v, want %v", got, want)
+	}
+}
+
+func TestCreateAssignsSameReviewersForSameSeed(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func() []PullRequestOption
+	}{
+		{"pr hash", func() []PullRequestOption {
+			return []PullRequestOption{WithDeterministicAssignment()}
+		}},
+		{"rand source", func() []PullRequestOption {
+			return []PullRequestOption{WithRandSource(rand.NewSource(7))}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			var seeds []int64
+			for range 2 {
+				repos := &assignmentRepos{}
+				pr, err := repos.service(&fakeTx{}, tt.opts()...).Create(context.Background(), "pr-1", "Add search", "u1")
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				got = append(got, pr.AssignedReviewers)
+				seeds = append(seeds, repos.decisions[0].Seed)
+			}
+			if !slices.Equal(got[0], got[1]) || seeds[0] != seeds[1] {
+				t.Fatalf("same seed and PR ID gave %v (seed %d), then %v (seed %d)", got[0], seeds[0], got[1], seeds[1])
+			}
+			if slices.Contains(got[0], "u1") {
+				t.Fatalf("author assigned as reviewer: %v", got[0])
+			}
+		})
+	}
+}
+
+func TestCreateWritesPRAndDecisionInOneTx(t *testing.T) {
+	repos := &assignmentRepos{}
+	tx := &fakeTx{}
+	if _, err := repos.service(tx).Create(context.Background(), "pr-1", "Add search", "u1"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if repos.writesOutTx != 0 {
+		t.Fatalf("%d writes outside the transaction", repos.writesOutTx)
+	}
+	if tx.committed != 1 || len(repos.decisions) != 1 {
+		t.Fatalf("committed %d transactions with %d decisions, want 1 and 1", tx.committed, len(repos.decisions))
+	}
+
+	// ошибка записи решения откатывает и сам PR
+	failing := &assignmentRepos{}
+	failing.recordErr = errors.New("insert failed")
+	tx = &fakeTx{}
+	if _, err := failing.service(tx).Create(context.Background(), "pr-2", "Add search", "u1"); !errors.Is(err, failing.recordErr) {
+		t.Fatalf("Create error = %v, want %v", err, failing.recordErr)
+	}
+	if tx.rolledBack != 1 || failing.writesOutTx != 0 {
+		t.Fatalf("rolled back %d transactions, %d writes outside, want 1 and 0", tx.rolledBack, failing.writesOutTx)
+	}
+}
+
+func TestReassignReadsAndWritesPRInOneTx(t *testing.T) {
+	repos := &assignmentRepos{stored: &domain.PullRequest{
+		ID:                "pr-1",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}}
+	tx := &fakeTx{}
+	pr, replacement, err := repos.service(tx, WithDeterministicAssignment()).Reassign(context.Background(), "pr-1", "u2")
+	if err != nil {
+		t.Fatalf("Reassign: %v", err)
+	}
+	if repos.readsOutTx != 0 || repos.writesOutTx != 0 {
+		t.Fatalf("%d reads and %d writes outside the transaction", repos.readsOutTx, repos.writesOutTx)
+	}
+	if tx.committed != 1 {
+		t.Fatalf("committed %d transactions, want 1", tx.committed)
+	}
+
+	// решение воспроизводимо по тому PR, который был записан
+	d := repos.decisions[0]
+	if !slices.Equal(d.Candidates, []string{"u5", "u4"}) {
+		t.Fatalf("candidates = %v, want the active members outside the locked PR", d.Candidates)
+	}
+	if got := PickReviewers(d.Candidates, 1, d.Seed); !slices.Equal(got, []string{replacement}) {
+		t.Fatalf("replaying the decision gave %v, want %s", got, replacement)
+	}
+	if !slices.Equal(pr.AssignedReviewers, repos.updated[0].AssignedReviewers) ||
+		slices.Contains(pr.AssignedReviewers, "u2") {
+		t.Fatalf("reviewers = %v, stored %v", pr.AssignedReviewers, repos.updated[0].AssignedReviewers)
+	}
+
+	// ревьювер, которого успели заменить, — NOT_ASSIGNED без записи
+	repos.stored.AssignedReviewers = []string{replacement, "u3"}
+	if _, _, err := repos.service(tx).Reassign(context.Background(), "pr-1", "u2"); !isCode(err, errs.CodeNotAssigned) {
+		t.Fatalf("Reassign of a replaced reviewer: %v, want %s", err, errs.CodeNotAssigned)
+	}
+	if len(repos.updated) != 1 || tx.rolledBack != 1 {
+		t.Fatalf("%d updates, %d rollbacks, want 1 and 1", len(repos.updated), tx.rolledBack)
+	}
+}
+
+func isCode(err error, code errs.ErrorCode) bool {
+	var appErr *errs.AppError
+	return errors.As(err, &appErr) && appErr.Code == code
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"avito/internal/auth"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
+	"avito/internal/tenant"
+	"avito/internal/tracing"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// apiKeyPrefix помогает опознать API-ключ этого сервиса, например в логах
+// сканеров секретов.
+const apiKeyPrefix = "prk_"
+
+type AuthService struct {
+	keys          repository.APIKeyRepository
+	users         repository.UserRepository
+	teams         repository.TeamRepository
+	bootstrapHash []byte
+	jwt           *auth.JWTVerifier
+}
+
+// AuthOption настраивает AuthService при создании.
+type AuthOption func(*AuthService)
+
+// WithBootstrapAdminKey задаёт ключ администратора из конфигурации. Он не
+// хранится в БД и нужен, чтобы выпустить первые ключи.
+func WithBootstrapAdminKey(secret string) AuthOption {
+	return func(s *AuthService) {
+		if secret != "" {
+			s.bootstrapHash = hashAPIKey(secret)
+		}
+	}
+}
+
+// WithJWTVerifier включает вход по JWT наряду с API-ключами.
+func WithJWTVerifier(v *auth.JWTVerifier) AuthOption {
+	return func(s *AuthService) {
+		s.jwt = v
+	}
+}
+
+func NewAuthService(
+	keys repository.APIKeyRepository,
+	users repository.UserRepository,
+	teams repository.TeamRepository,
+	opts ...AuthOption,
+) *AuthService {
+	s := &AuthService{keys: keys, users: users, teams: teams}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Authenticate находит вызывающего по секрету API-ключа или по JWT.
+func (s *AuthService) Authenticate(ctx context.Context, secret string) (_ *domain.Principal, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authenticate")
+	defer func() { tracing.End(span, err) }()
+
+	hash := hashAPIKey(secret)
+	if s.bootstrapHash != nil && subtle.ConstantTimeCompare(hash, s.bootstrapHash) == 1 {
+		// без OrgID: администратор платформы работает с любой организацией
+		return &domain.Principal{Subject: "bootstrap", Name: "bootstrap admin", Role: domain.RoleAdmin}, nil
+	}
+
+	if s.jwt != nil && !strings.HasPrefix(secret, apiKeyPrefix) && auth.LooksLikeJWT(secret) {
+		span.SetAttributes(attribute.String("auth.method", "jwt"))
+		p, err := s.jwt.Verify(ctx, secret)
+		if err != nil {
+			logging.FromContext(ctx).InfoContext(ctx, "jwt rejected", "error", err.Error())
+			return nil, errs.New(errs.CodeUnauthorized, "invalid token")
+		}
+		if p.TeamName != "" {
+			// права лида сверяются по ID команды: claim team указывает на
+			// текущее имя, которое после переименования обновляет IdP
+			team, err := s.teams.GetTeam(tenant.WithOrg(ctx, p.OrgID), p.TeamName)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				logging.FromContext(ctx).InfoContext(ctx, "jwt rejected", "error", "unknown team "+p.TeamName)
+				return nil, errs.New(errs.CodeUnauthorized, "invalid token")
+			case err != nil:
+				return nil, err
+			}
+			p.TeamID = team.ID
+		}
+		return p, nil
+	}
+	span.SetAttributes(attribute.String("auth.method", "api_key"))
+
+	key, err := s.keys.GetAPIKeyByHash(ctx, hash)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && key.RevokedAt != nil) {
+		return nil, errs.New(errs.CodeUnauthorized, "invalid or revoked api key")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.keys.TouchAPIKey(ctx, key.ID); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "update api key last_used_at", "api_key_id", key.ID, "error", err.Error())
+	}
+
+	return &domain.Principal{
+		Subject:  "key:" + strconv.FormatInt(key.ID, 10),
+		Name:     key.Name,
+		Role:     key.Role,
+		OrgID:    key.OrgID,
+		UserID:   key.UserID,
+		TeamName: key.TeamName,
+		TeamID:   key.TeamID,
+	}, nil
+}
+
+// CreateKey выпускает ключ организации запроса и возвращает его вместе
+// с секретом. Секрет показывается один раз: в БД сохраняется только его хэш.
+func (s *AuthService) CreateKey(ctx context.Context, key domain.APIKey) (_ *domain.APIKey, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateKey", attribute.String("api_key.role", string(key.Role)))
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil, "", errForbidden("only admins can manage api keys")
+	}
+
+	if key.Role == domain.RoleTeamLead {
+		if _, err := s.teams.GetTeam(ctx, key.TeamName); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, "", errs.New(errs.CodeNotFound, "team not found")
+			}
+			return nil, "", err
+		}
+	} else {
+		key.TeamName = ""
+	}
+	if key.UserID != "" {
+		if _, err := s.users.GetUser(ctx, key.UserID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, "", errs.New(errs.CodeNotFound, "user not found")
+			}
+			return nil, "", err
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	key.Prefix = secret[:len(apiKeyPrefix)+8]
+
+	created, err := s.keys.CreateAPIKey(ctx, key, hashAPIKey(secret))
+	if err != nil {
+		return nil, "", err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "api key created",
+		"api_key_id", created.ID, "role", string(created.Role), "user_id", created.UserID, "team_name", created.TeamName)
+	return created, secret, nil
+}
+
+func (s *AuthService) ListKeys(ctx context.Context) (_ []domain.APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ListKeys")
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil, errForbidden("only admins can manage api keys")
+	}
+
+	return s.keys.ListAPIKeys(ctx)
+}
+
+// RevokeKey отзывает ключ; повторный отзыв не меняет время отзыва.
+func (s *AuthService) RevokeKey(ctx context.Context, id int64) (_ *domain.APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeKey", attribute.Int64("api_key.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil, errForbidden("only admins can manage api keys")
+	}
+
+	key, err := s.keys.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeNotFound, "api key not found")
+		}
+		return nil, err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "api key revoked", "api_key_id", id)
+	return key, nil
+}
+
+func hashAPIKey(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
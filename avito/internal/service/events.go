@@ -0,0 +1,38 @@
+package service
+
+import (
+	"avito/internal/domain"
+	"avito/internal/events"
+	"avito/internal/repository"
+	"avito/internal/tenant"
+	"avito/internal/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// EventService отдаёт события PR: журнал — клиентам, которые догоняют
+// пропущенное, и живые события через брокер процесса.
+type EventService struct {
+	events repository.EventRepository
+	broker *events.Broker
+}
+
+func NewEventService(er repository.EventRepository, broker *events.Broker) *EventService {
+	return &EventService{events: er, broker: broker}
+}
+
+// Subscribe подписывает на новые события организации из ctx, подходящие
+// под f; buffer — см. events.Broker.Subscribe. Подписку нужно закрыть,
+// когда она больше не нужна.
+func (s *EventService) Subscribe(ctx context.Context, f repository.EventFilter, buffer int) *events.Subscription {
+	return s.broker.Subscribe(tenant.FromContext(ctx), f, buffer)
+}
+
+// Replay возвращает до limit событий журнала с ID больше afterID.
+func (s *EventService) Replay(ctx context.Context, f repository.EventFilter, afterID int64, limit int) (_ []domain.Event, err error) {
+	ctx, span := tracing.Start(ctx, "EventService.Replay", attribute.Int64("event.after_id", afterID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.events.ListEvents(ctx, f, afterID, limit)
+}
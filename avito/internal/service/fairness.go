@@ -0,0 +1,151 @@
+package service
+
+import (
+	"avito/internal/errs"
+	"avito/internal/repository"
+	"avito/internal/tracing"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultFairnessWindow — окно отчёта, если from не задан.
+const DefaultFairnessWindow = 30 * 24 * time.Hour
+
+// DefaultFairnessTolerance — допустимое отклонение доли назначений
+// от идеальной, после которого участник считается пере- или недогруженным.
+const DefaultFairnessTolerance = 0.25
+
+type MemberFairness struct {
+	UserID      string
+	IsActive    bool
+	DaysActive  float64
+	Assignments int64
+	// Share — фактическая доля назначений команды.
+	Share float64
+	// IdealShare — доля, пропорциональная числу активных дней.
+	IdealShare float64
+	// LoadRatio — Share / IdealShare; 1 означает идеальную нагрузку.
+	LoadRatio float64
+}
+
+type FairnessReport struct {
+	TeamName         string
+	From             time.Time
+	To               time.Time
+	Tolerance        float64
+	TotalAssignments int64
+	// Gini — коэффициент Джини по LoadRatio участников с активными днями:
+	// 0 — нагрузка распределена идеально, 1 — вся на одном человеке.
+	Gini        float64
+	Members     []MemberFairness
+	Overloaded  []string
+	Underloaded []string
+}
+
+// GetFairnessReport строит отчёт о распределении назначений в команде за окно
+// [from, to) с учётом числа дней, когда участники были активны.
+func (s *PullRequestService) GetFairnessReport(
+	ctx context.Context,
+	teamName string,
+	from, to time.Time,
+	tolerance float64,
+) (_ *FairnessReport, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetFairnessReport",
+		attribute.String("team.name", teamName),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.teams.GetTeam(ctx, teamName); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeNotFound, "team not found")
+		}
+		return nil, err
+	}
+
+	loads, err := s.prs.GetReviewLoad(ctx, teamName, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := computeFairness(loads, tolerance)
+	report.TeamName = teamName
+	report.From = from
+	report.To = to
+	return report, nil
+}
+
+func computeFairness(loads []repository.MemberLoad, tolerance float64) *FairnessReport {
+	report := &FairnessReport{
+		Tolerance:   tolerance,
+		Members:     make([]MemberFairness, 0, len(loads)),
+		Overloaded:  make([]string, 0),
+		Underloaded: make([]string, 0),
+	}
+
+	var totalDays float64
+	for _, l := range loads {
+		report.TotalAssignments += l.Assignments
+		totalDays += l.DaysActive
+	}
+
+	ratios := make([]float64, 0, len(loads))
+	for _, l := range loads {
+		m := MemberFairness{
+			UserID:      l.UserID,
+			IsActive:    l.IsActive,
+			DaysActive:  l.DaysActive,
+			Assignments: l.Assignments,
+		}
+		if report.TotalAssignments > 0 {
+			m.Share = float64(l.Assignments) / float64(report.TotalAssignments)
+		}
+		if totalDays > 0 {
+			m.IdealShare = l.DaysActive / totalDays
+		}
+
+		// участник без активных дней в окне не мог получать назначения,
+		// поэтому в оценке нагрузки он не участвует
+		if m.IdealShare > 0 && report.TotalAssignments > 0 {
+			m.LoadRatio = m.Share / m.IdealShare
+			ratios = append(ratios, m.LoadRatio)
+
+			switch {
+			case m.LoadRatio > 1+tolerance:
+				report.Overloaded = append(report.Overloaded, m.UserID)
+			case m.LoadRatio < 1-tolerance:
+				report.Underloaded = append(report.Underloaded, m.UserID)
+			}
+		}
+		report.Members = append(report.Members, m)
+	}
+
+	report.Gini = gini(ratios)
+	return report
+}
+
+// gini считает коэффициент Джини по отсортированной выборке:
+// G = Σ (2i - n - 1) x_i / (n Σ x_i), i = 1..n.
+func gini(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+
+	var sum, weighted float64
+	n := float64(len(sorted))
+	for i, x := range sorted {
+		sum += x
+		weighted += (2*float64(i+1) - n - 1) * x
+	}
+	if sum == 0 {
+		return 0
+	}
+	return math.Max(0, weighted/(n*sum))
+}
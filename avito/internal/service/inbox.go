@@ -0,0 +1,165 @@
+package service
+
+import (
+	"avito/internal/auth"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/events"
+	"avito/internal/repository"
+	"avito/internal/tenant"
+	"avito/internal/tracing"
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// InboxService поддерживает входящие ревью пользователя — открытые PR,
+// где он назначен ревьювером, — в актуальном состоянии по событиям PR.
+type InboxService struct {
+	users  repository.UserRepository
+	prs    repository.PullRequestRepository
+	broker *events.Broker
+}
+
+func NewInboxService(ur repository.UserRepository, pr repository.PullRequestRepository, broker *events.Broker) *InboxService {
+	return &InboxService{users: ur, prs: pr, broker: broker}
+}
+
+// InboxChangeKind — добавление PR во входящие или удаление из них.
+type InboxChangeKind string
+
+const (
+	InboxAdded   InboxChangeKind = "added"
+	InboxRemoved InboxChangeKind = "removed"
+)
+
+// InboxChange — одно изменение входящих.
+type InboxChange struct {
+	Kind        InboxChangeKind
+	PullRequest domain.PullRequest
+	// Event — событие, вызвавшее изменение; nil при сверке после Resync.
+	Event *domain.Event
+}
+
+// Inbox — входящие ревью одного пользователя вместе с подпиской на
+// события, которые их меняют. Не потокобезопасен.
+type Inbox struct {
+	UserID string
+	orgID  string
+	buffer int
+	sub    *events.Subscription
+	items  map[string]domain.PullRequest
+}
+
+// Open подписывается на события пользователя userID и загружает его
+// текущие входящие. buffer — см. events.Broker.Subscribe. Чужие входящие
+// доступны только администратору и лиду команды пользователя. Inbox нужно
+// закрыть, когда он больше не нужен.
+func (s *InboxService) Open(ctx context.Context, userID string, buffer int) (_ *Inbox, err error) {
+	ctx, span := tracing.Start(ctx, "InboxService.Open", attribute.String("user.id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	u, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeNotFound, "user not found")
+		}
+		return nil, err
+	}
+	if !auth.CanReadInbox(ctx, userID, u.TeamID) {
+		return nil, errForbidden("not allowed to read the inbox of user " + userID)
+	}
+
+	in := &Inbox{UserID: userID, orgID: tenant.FromContext(ctx), buffer: buffer}
+	if _, err := s.Resync(ctx, in); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// Resync подписывается заново и сверяет входящие с базой; возвращает
+// изменения относительно прежнего состояния. Нужен, когда подписка снята
+// с events.ErrReset и часть событий могла потеряться. Работает в
+// организации, где входящие открыты, независимо от ctx.
+func (s *InboxService) Resync(ctx context.Context, in *Inbox) (_ []InboxChange, err error) {
+	ctx, span := tracing.Start(tenant.WithOrg(ctx, in.orgID), "InboxService.Resync", attribute.String("user.id", in.UserID))
+	defer func() { tracing.End(span, err) }()
+
+	// подписка раньше загрузки: события между ними применятся поверх
+	// загруженного, но в порядке записи, так что итог сойдётся с базой
+	if in.sub != nil {
+		in.sub.Close()
+	}
+	in.sub = s.broker.Subscribe(in.orgID, repository.EventFilter{UserID: in.UserID}, in.buffer)
+
+	prs, err := s.prs.GetOpenPRsByReviewer(ctx, in.UserID)
+	if err != nil {
+		in.sub.Close()
+		return nil, err
+	}
+
+	fresh := make(map[string]domain.PullRequest, len(prs))
+	for _, pr := range prs {
+		fresh[pr.ID] = pr
+	}
+
+	var changes []InboxChange
+	for id, pr := range in.items {
+		if _, ok := fresh[id]; !ok {
+			changes = append(changes, InboxChange{Kind: InboxRemoved, PullRequest: pr})
+		}
+	}
+	for id, pr := range fresh {
+		if _, ok := in.items[id]; !ok && in.items != nil {
+			changes = append(changes, InboxChange{Kind: InboxAdded, PullRequest: pr})
+		}
+	}
+	in.items = fresh
+	return changes, nil
+}
+
+// Events отдаёт события подписки; см. events.Subscription.
+func (in *Inbox) Events() <-chan domain.Event {
+	return in.sub.Events()
+}
+
+// Err — причина, по которой подписка снята; см. events.Subscription.Err.
+func (in *Inbox) Err() error {
+	return in.sub.Err()
+}
+
+func (in *Inbox) Close() {
+	in.sub.Close()
+}
+
+// Items — текущие входящие, по ID PR.
+func (in *Inbox) Items() []domain.PullRequest {
+	items := make([]domain.PullRequest, 0, len(in.items))
+	for _, pr := range in.items {
+		items = append(items, pr)
+	}
+	slices.SortFunc(items, func(a, b domain.PullRequest) int { return cmp.Compare(a.ID, b.ID) })
+	return items
+}
+
+// Apply применяет событие: PR входит во входящие, пока он открыт
+// и пользователь среди его ревьюверов. Повторное или уже учтённое
+// событие изменений не даёт.
+func (in *Inbox) Apply(e domain.Event) (InboxChange, bool) {
+	pr := e.PullRequest
+	want := pr.Status == domain.PRStatusOpen && slices.Contains(pr.AssignedReviewers, in.UserID)
+	_, have := in.items[pr.ID]
+
+	switch {
+	case want && !have:
+		in.items[pr.ID] = pr
+		return InboxChange{Kind: InboxAdded, PullRequest: pr, Event: &e}, true
+	case !want && have:
+		delete(in.items, pr.ID)
+		return InboxChange{Kind: InboxRemoved, PullRequest: pr, Event: &e}, true
+	}
+	return InboxChange{}, false
+}
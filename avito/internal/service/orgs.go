@@ -0,0 +1,108 @@
+package service
+
+import (
+	"avito/internal/auth"
+	"avito/internal/domain"
+	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/repository"
+	"avito/internal/tenant"
+	"avito/internal/tracing"
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type OrgService struct {
+	orgs repository.OrgRepository
+}
+
+func NewOrgService(or repository.OrgRepository) *OrgService {
+	return &OrgService{orgs: or}
+}
+
+// Resolve определяет организацию запроса. Вызывающий, привязанный
+// к организации, работает только в ней; администратор платформы и запросы
+// без аутентификации выбирают её через requested, по умолчанию — tenant.Default.
+func (s *OrgService) Resolve(ctx context.Context, requested string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "OrgService.Resolve", attribute.String("org.requested", requested))
+	defer func() { tracing.End(span, err) }()
+
+	if p, ok := auth.FromContext(ctx); ok && p.OrgID != "" {
+		if requested != "" && requested != p.OrgID {
+			return "", errForbidden("not a member of organization " + requested)
+		}
+		return p.OrgID, nil
+	}
+
+	if requested == "" {
+		requested = tenant.Default
+	}
+	if !tenant.ValidID(requested) {
+		return "", errs.New(errs.CodeNotFound, "organization not found")
+	}
+	if _, err := s.orgs.GetOrg(ctx, requested); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", errs.New(errs.CodeNotFound, "organization not found")
+		}
+		return "", err
+	}
+	return requested, nil
+}
+
+// CreateOrg создаёт организацию. Доступно только администратору платформы.
+func (s *OrgService) CreateOrg(ctx context.Context, org domain.Organization) (_ *domain.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "OrgService.CreateOrg", attribute.String("org.id", org.ID))
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.IsPlatformAdmin(ctx) {
+		return nil, errForbidden("only platform admins can create organizations")
+	}
+
+	created, err := s.orgs.CreateOrg(ctx, org)
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, errs.New(errs.CodeOrgExists, "organization already exists")
+		}
+		return nil, err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "organization created", "org_id", created.ID)
+	return created, nil
+}
+
+func (s *OrgService) GetOrg(ctx context.Context, id string) (_ *domain.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "OrgService.GetOrg", attribute.String("org.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	// чужая организация неотличима от несуществующей
+	if !auth.CanAccessOrg(ctx, id) {
+		return nil, errs.New(errs.CodeNotFound, "organization not found")
+	}
+
+	org, err := s.orgs.GetOrg(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeNotFound, "organization not found")
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+// ListOrgs возвращает все организации администратору платформы
+// и только свою — остальным.
+func (s *OrgService) ListOrgs(ctx context.Context) (_ []domain.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "OrgService.ListOrgs")
+	defer func() { tracing.End(span, err) }()
+
+	if p, ok := auth.FromContext(ctx); ok && p.OrgID != "" {
+		org, err := s.orgs.GetOrg(ctx, p.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.Organization{*org}, nil
+	}
+	return s.orgs.ListOrgs(ctx)
+}
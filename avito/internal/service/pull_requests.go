@@ -1,51 +1,79 @@
 package service
 
 import (
+	"avito/internal/auth"
 	"avito/internal/domain"
 	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/metrics"
 	"avito/internal/repository"
+	"avito/internal/tracing"
 	"context"
 	"errors"
-	"math/rand"
 	"slices"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// DefaultReviewerCount — число ревьюверов на новый PR по умолчанию.
+const DefaultReviewerCount = 2
+
 type PullRequestService struct {
-	prs   repository.PullRequestRepository
-	users repository.UserRepository
-	teams repository.TeamRepository
+	prs           repository.PullRequestRepository
+	users         repository.UserRepository
+	teams         repository.TeamRepository
+	picker        *reviewerPicker
+	reviewerCount int
+	tx            repository.Transactor
+	// events задан WithEventLog.
+	events repository.EventRepository
 }
 
 func NewPullRequestService(
 	prRepo repository.PullRequestRepository,
 	userRepo repository.UserRepository,
 	teamRepo repository.TeamRepository,
+	tx repository.Transactor,
+	opts ...PullRequestOption,
 ) *PullRequestService {
-	return &PullRequestService{
-		prs:   prRepo,
-		users: userRepo,
-		teams: teamRepo,
+	s := &PullRequestService{
+		prs:           prRepo,
+		users:         userRepo,
+		teams:         teamRepo,
+		tx:            tx,
+		picker:        newReviewerPicker(),
+		reviewerCount: DefaultReviewerCount,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Create создаёт новый PR и автоматически назначает до двух активных ревьюверов
-// из команды автора, исключая самого автора.
+// Create создаёт новый PR и автоматически назначает до reviewerCount (по
+// умолчанию двух) активных ревьюверов из команды автора, исключая самого автора.
 func (s *PullRequestService) Create(
 	ctx context.Context,
 	id string,
 	name string,
 	authorID string,
-) (*domain.PullRequest, error) {
+) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Create",
+		attribute.String("pr.id", id),
+		attribute.String("pr.author_id", authorID),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// проверяем, что PR с таким ID ещё не существует
-	if _, err := s.prs.GetPR(id); err == nil {
+	if _, err := s.prs.GetPR(ctx, id); err == nil {
 		return nil, errs.New(errs.CodePRExists, "pull_request_id already exists")
 	} else if !errors.Is(err, repository.ErrNotFound) {
 		return nil, err
 	}
 
 	// получаем автора и его команду
-	author, err := s.users.GetUser(authorID)
+	author, err := s.users.GetUser(ctx, authorID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errs.New(errs.CodeNotFound, "author not found")
@@ -53,7 +81,7 @@ func (s *PullRequestService) Create(
 		return nil, err
 	}
 
-	team, err := s.teams.GetTeam(author.TeamName)
+	team, err := s.teams.GetTeam(ctx, author.TeamName)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errs.New(errs.CodeNotFound, "team not found")
@@ -73,15 +101,13 @@ func (s *PullRequestService) Create(
 		candidates = append(candidates, m.UserID)
 	}
 
-	// перемешиваем и берём до двух
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
-
-	assigned := candidates
-	if len(assigned) > 2 {
-		assigned = assigned[:2]
-	}
+	// выбираем до reviewerCount по seed выбранной стратегии
+	strategy, seed := s.picker.seed(id)
+	assigned := PickReviewers(candidates, s.reviewerCount, seed)
+	span.SetAttributes(
+		attribute.Int("reviewers.candidates", len(candidates)),
+		attribute.StringSlice("pr.reviewers", assigned),
+	)
 
 	now := time.Now().UTC()
 	pr := domain.PullRequest{
@@ -93,83 +119,311 @@ func (s *PullRequestService) Create(
 		CreatedAt:         &now,
 	}
 
-	if err := s.prs.CreatePR(pr); err != nil {
-		if errors.Is(err, repository.ErrAlreadyExists) {
-			return nil, errs.New(errs.CodePRExists, "pull_request_id already exists")
+	decision := domain.AssignmentDecision{
+		PRID:       id,
+		Action:     domain.AssignmentActionCreate,
+		Strategy:   strategy,
+		Seed:       seed,
+		Candidates: candidates,
+		Assigned:   assigned,
+		ActedBy:    auth.Actor(ctx),
+		DecidedAt:  now,
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.prs.CreatePR(ctx, pr); err != nil {
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				return errs.New(errs.CodePRExists, "pull_request_id already exists")
+			}
+			return err
 		}
+		if err := s.prs.RecordAssignment(ctx, decision); err != nil {
+			return err
+		}
+		return s.record(ctx, domain.Event{Type: domain.EventPRCreated, PullRequest: pr})
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	logging.FromContext(ctx).InfoContext(ctx, "pull request created",
+		"pr_id", id, "user_id", authorID, "team_name", team.TeamName, "reviewers", assigned)
+	metrics.PRsCreated.Inc()
 	return &pr, nil
 }
 
+// Get возвращает PR по идентификатору.
+func (s *PullRequestService) Get(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Get", attribute.String("pr.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.getPR(ctx, prID)
+}
+
+// GetPRs возвращает PR по списку ID одним запросом; несуществующие
+// в результат не попадают.
+func (s *PullRequestService) GetPRs(ctx context.Context, ids []string) (_ []domain.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetPRs", attribute.Int("pr.count", len(ids)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.prs.GetPRs(ctx, ids)
+}
+
+// GetOpenReviews возвращает открытые PR, назначенные каждому из
+// пользователей; ключ — user_id.
+func (s *PullRequestService) GetOpenReviews(ctx context.Context, userIDs []string) (_ map[string][]domain.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetOpenReviews", attribute.Int("user.count", len(userIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.prs.GetOpenPRsByReviewers(ctx, userIDs)
+}
+
 // Merge переводит PR в статус MERGED и устанавливает mergedAt.
-func (s *PullRequestService) Merge(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	pr, err := s.prs.GetPR(prID)
-	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, errs.New(errs.CodeNotFound, "pull request not found")
+func (s *PullRequestService) Merge(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Merge", attribute.String("pr.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	merged := domain.PRStatusMerged
+	return s.update(ctx, prID, PullRequestPatch{Status: &merged})
+}
+
+// PullRequestPatch — частичное изменение PR; nil-поля не меняются.
+type PullRequestPatch struct {
+	Name *string
+	// Status допускает только MERGED: смерженный PR не переоткрывается.
+	Status *domain.PullRequestStatus
+}
+
+// Update меняет название PR и/или переводит его в MERGED. Смерженный PR
+// не меняется: повторный перевод в MERGED идемпотентен, остальное — PR_MERGED.
+func (s *PullRequestService) Update(ctx context.Context, prID string, patch PullRequestPatch) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Update", attribute.String("pr.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.update(ctx, prID, patch)
+}
+
+func (s *PullRequestService) update(ctx context.Context, prID string, patch PullRequestPatch) (*domain.PullRequest, error) {
+	merge := patch.Status != nil && *patch.Status == domain.PRStatusMerged
+	var pr *domain.PullRequest
+	var merged bool
+
+	// PR читается с блокировкой: иначе запись списка ревьюверов из старого
+	// чтения затрёт параллельное переназначение
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = s.prs.GetPRForUpdate(ctx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errs.New(errs.CodeNotFound, "pull request not found")
+			}
+			return err
 		}
+
+		rename := patch.Name != nil && *patch.Name != pr.Name
+		if pr.Status == domain.PRStatusMerged {
+			if rename || (patch.Status != nil && !merge) {
+				return errs.New(errs.CodePRMerged, "cannot modify merged pull request")
+			}
+			return nil
+		}
+		if !merge && !rename {
+			return nil
+		}
+
+		if rename {
+			pr.Name = *patch.Name
+		}
+		if merge {
+			now := time.Now().UTC()
+			pr.Status = domain.PRStatusMerged // "MERGED"
+			pr.MergedAt = &now
+			pr.MergedBy = auth.Actor(ctx)
+		}
+
+		if err := s.prs.UpdatePR(ctx, *pr); err != nil {
+			return err
+		}
+		if !merge {
+			return nil
+		}
+		merged = true
+		return s.record(ctx, domain.Event{Type: domain.EventPRMerged, PullRequest: *pr})
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if pr.Status == domain.PRStatusMerged {
-		return pr, nil
+	if merged {
+		logging.FromContext(ctx).InfoContext(ctx, "pull request merged", "pr_id", prID, "merged_by", pr.MergedBy)
+		metrics.PRsMerged.Inc()
 	}
+	return pr, nil
+}
 
-	now := time.Now().UTC()
-	pr.Status = domain.PRStatusMerged // "MERGED"
-	pr.MergedAt = &now
+// record записывает событие в журнал, если он подключён.
+func (s *PullRequestService) record(ctx context.Context, e domain.Event) error {
+	if s.events == nil {
+		return nil
+	}
+	e.ActedBy = auth.Actor(ctx)
+	_, err := s.events.AppendEvent(ctx, e)
+	return err
+}
 
-	if err := s.prs.UpdatePR(*pr); err != nil {
+func (s *PullRequestService) getPR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.prs.GetPR(ctx, prID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeNotFound, "pull request not found")
+		}
 		return nil, err
 	}
-
 	return pr, nil
 }
 
 // Reassign выполняет переназначение одного ревьювера на другого
-// и возвращает новый PR и ID подставленного ревьювера.
+// и возвращает новый PR и ID подставленного ревьювера. PR читается
+// с блокировкой в той же транзакции, что и записывается: параллельные
+// переназначения не затирают друг друга, а кандидаты в журнале решений
+// совпадают с сохранённым состоянием.
 func (s *PullRequestService) Reassign(
 	ctx context.Context,
 	prID string,
 	oldUserID string,
-) (*domain.PullRequest, string, error) {
-	pr, err := s.prs.GetPR(prID)
-	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, "", errs.New(errs.CodeNotFound, "pull request not found")
+) (_ *domain.PullRequest, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Reassign",
+		attribute.String("pr.id", prID),
+		attribute.String("pr.old_reviewer_id", oldUserID),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	var pr *domain.PullRequest
+	var done *reassignment
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		pr, err = s.lockAssigned(ctx, prID, oldUserID)
+		if err != nil {
+			return err
+		}
+
+		// получаем пользователя и его команду
+		reviewer, err := s.users.GetUser(ctx, oldUserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errs.New(errs.CodeNotFound, "reviewer not found")
+			}
+			return err
 		}
+
+		team, err := s.teams.GetTeam(ctx, reviewer.TeamName)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errs.New(errs.CodeNotFound, "team not found")
+			}
+			return err
+		}
+
+		done, err = s.replaceReviewer(ctx, pr, oldUserID, team)
+		return err
+	})
+	if err != nil {
 		return nil, "", err
 	}
 
-	if pr.Status == domain.PRStatusMerged {
-		return nil, "", errs.New(errs.CodePRMerged, "pull request already merged")
-	}
+	s.logReassigned(ctx, done)
+	return pr, done.newUserID, nil
+}
 
-	// проверяем, что oldUserID действительно назначен ревьювером
-	if !slices.Contains(pr.AssignedReviewers, oldUserID) {
-		return nil, "", errs.New(errs.CodeNotAssigned, "reviewer is not assigned to this PR")
+// reassignWithinTeam заменяет ревьювера oldUserID в PR prID кандидатом из
+// команды teamName. Используется при изменении состава команд, когда
+// команда ревьювера уже не совпадает с командой автора. Вызывается внутри
+// транзакции операции над командой: замену она логирует после фиксации
+// через logReassigned. Если PR успели смержить или ревьювера уже заменили,
+// возвращает PR_MERGED или NOT_ASSIGNED.
+func (s *PullRequestService) reassignWithinTeam(
+	ctx context.Context,
+	prID string,
+	oldUserID string,
+	teamName string,
+) (_ *reassignment, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.reassignWithinTeam",
+		attribute.String("pr.id", prID),
+		attribute.String("pr.old_reviewer_id", oldUserID),
+		attribute.String("team.name", teamName),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	pr, err := s.lockAssigned(ctx, prID, oldUserID)
+	if err != nil {
+		return nil, err
 	}
 
-	// получаем пользователя и его команду
-	reviewer, err := s.users.GetUser(oldUserID)
+	team, err := s.teams.GetTeam(ctx, teamName)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return nil, "", errs.New(errs.CodeNotFound, "reviewer not found")
+			return nil, errs.New(errs.CodeNotFound, "team not found")
 		}
-		return nil, "", err
+		return nil, err
 	}
 
-	team, err := s.teams.GetTeam(reviewer.TeamName)
+	return s.replaceReviewer(ctx, pr, oldUserID, team)
+}
+
+// lockAssigned читает PR с блокировкой и проверяет, что он открыт
+// и oldUserID назначен на него ревьювером. Вызывается внутри транзакции.
+func (s *PullRequestService) lockAssigned(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, error) {
+	pr, err := s.prs.GetPRForUpdate(ctx, prID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return nil, "", errs.New(errs.CodeNotFound, "team not found")
+			return nil, errs.New(errs.CodeNotFound, "pull request not found")
 		}
-		return nil, "", err
+		return nil, err
+	}
+
+	if pr.Status == domain.PRStatusMerged {
+		return nil, errs.New(errs.CodePRMerged, "pull request already merged")
 	}
 
-	// кандидаты: активные из команды oldUserID, кроме автора и уже назначенных
+	// проверяем, что oldUserID действительно назначен ревьювером
+	if !slices.Contains(pr.AssignedReviewers, oldUserID) {
+		return nil, errs.New(errs.CodeNotAssigned, "reviewer is not assigned to this PR")
+	}
+	return pr, nil
+}
+
+// reassignment — сохранённая замена ревьювера. В лог и метрики она
+// попадает только после фиксации транзакции, в которой записана.
+type reassignment struct {
+	prID      string
+	teamName  string
+	oldUserID string
+	newUserID string
+	actedBy   string
+}
+
+func (s *PullRequestService) logReassigned(ctx context.Context, r *reassignment) {
+	logging.FromContext(ctx).InfoContext(ctx, "reviewer reassigned",
+		"pr_id", r.prID, "team_name", r.teamName, "old_user_id", r.oldUserID, "new_user_id", r.newUserID,
+		"acted_by", r.actedBy)
+	metrics.Reassignments.Inc()
+}
+
+// replaceReviewer выбирает замену для oldUserID среди активных участников team,
+// сохраняет PR и фиксирует решение в журнале назначений. Вызывается внутри
+// транзакции, в которой pr прочитан с блокировкой.
+func (s *PullRequestService) replaceReviewer(
+	ctx context.Context,
+	pr *domain.PullRequest,
+	oldUserID string,
+	team *domain.Team,
+) (_ *reassignment, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.replaceReviewer",
+		attribute.String("pr.id", pr.ID),
+		attribute.String("pr.old_reviewer_id", oldUserID),
+		attribute.String("team.name", team.TeamName),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	// кандидаты: активные из команды, кроме автора и уже назначенных
 	already := make(map[string]struct{}, len(pr.AssignedReviewers)+1)
 	already[pr.AuthorID] = struct{}{}
 	for _, rid := range pr.AssignedReviewers {
@@ -188,14 +442,17 @@ func (s *PullRequestService) Reassign(
 	}
 
 	if len(candidates) == 0 {
-		return nil, "", errs.New(errs.CodeNoCandidate, "no active replacement candidate in team")
+		metrics.NoCandidate.Inc()
+		return nil, errs.New(errs.CodeNoCandidate, "no active replacement candidate in team")
 	}
 
-	// выбираем случайного кандидата
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
-	replacement := candidates[0]
+	// выбираем одного кандидата по seed выбранной стратегии
+	strategy, seed := s.picker.seed(pr.ID)
+	replacement := PickReviewers(candidates, 1, seed)[0]
+	span.SetAttributes(
+		attribute.Int("reviewers.candidates", len(candidates)),
+		attribute.String("pr.new_reviewer_id", replacement),
+	)
 
 	// заменяем oldUserID на replacement
 	for i, rid := range pr.AssignedReviewers {
@@ -205,40 +462,119 @@ func (s *PullRequestService) Reassign(
 		}
 	}
 
-	if err := s.prs.UpdatePR(*pr); err != nil {
-		return nil, "", err
+	decision := domain.AssignmentDecision{
+		PRID:           pr.ID,
+		Action:         domain.AssignmentActionReassign,
+		Strategy:       strategy,
+		Seed:           seed,
+		Candidates:     candidates,
+		Assigned:       []string{replacement},
+		ReplacedUserID: oldUserID,
+		ActedBy:        auth.Actor(ctx),
+		DecidedAt:      time.Now().UTC(),
+	}
+	if err := s.prs.UpdatePR(ctx, *pr); err != nil {
+		return nil, err
+	}
+	if err := s.prs.RecordAssignment(ctx, decision); err != nil {
+		return nil, err
+	}
+	err = s.record(ctx, domain.Event{
+		Type:          domain.EventPRReassigned,
+		PullRequest:   *pr,
+		OldReviewerID: oldUserID,
+		NewReviewerID: replacement,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return pr, replacement, nil
+	return &reassignment{
+		prID:      pr.ID,
+		teamName:  team.TeamName,
+		oldUserID: oldUserID,
+		newUserID: replacement,
+		actedBy:   decision.ActedBy,
+	}, nil
 }
 
-// GetUserReviews возвращает список PR, назначенных на конкретного пользователя,
+// GetUserReviews возвращает страницу PR, назначенных на конкретного пользователя,
 // в виде полного доменного объекта PR; хендлер уже маппит его в PullRequestShort.
-func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+func (s *PullRequestService) GetUserReviews(
+	ctx context.Context,
+	userID string,
+	f repository.ReviewFilter,
+) (_ repository.ReviewPage, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetUserReviews", attribute.String("user.id", userID))
+	defer func() { tracing.End(span, err) }()
 
-	if _, err := s.users.GetUser(userID); err != nil {
+	if _, err := s.users.GetUser(ctx, userID); err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return nil, errs.New(errs.CodeNotFound, "user not found")
+			return repository.ReviewPage{}, errs.New(errs.CodeNotFound, "user not found")
 		}
-		return nil, err
+		return repository.ReviewPage{}, err
 	}
 
-	prs, err := s.prs.GetPRsByReviewer(userID)
-	if err != nil {
+	page, err := s.prs.GetPRsByReviewer(ctx, userID, f)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return page, errs.New(errs.CodeInvalidCursor, "cursor is invalid or was issued for another sort")
+	}
+	return page, err
+}
+
+// GetAssignmentDecisions возвращает журнал решений о назначении ревьюверов
+// для PR в хронологическом порядке.
+func (s *PullRequestService) GetAssignmentDecisions(ctx context.Context, prID string) (_ []domain.AssignmentDecision, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetAssignmentDecisions", attribute.String("pr.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.prs.GetPR(ctx, prID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errs.New(errs.CodeNotFound, "pull request not found")
+		}
 		return nil, err
 	}
 
-	return prs, nil
+	return s.prs.GetAssignmentDecisions(ctx, prID)
+}
+
+// ListPRs возвращает страницу PR по фильтру.
+func (s *PullRequestService) ListPRs(ctx context.Context, f repository.PRFilter) (_ repository.PRPage, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.ListPRs")
+	defer func() { tracing.End(span, err) }()
+
+	page, err := s.prs.ListPRs(ctx, f)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return page, errs.New(errs.CodeInvalidCursor, "cursor is invalid or was issued for another sort")
+	}
+	return page, err
 }
 
 // ReassignReviewer — тонкая обёртка над Reassign, которая
 // возвращает только ошибку; используется в массовой деактивации.
-func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUserID string) error {
-	_, _, err := s.Reassign(ctx, prID, oldUserID)
+func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.ReassignReviewer",
+		attribute.String("pr.id", prID),
+		attribute.String("pr.old_reviewer_id", oldUserID),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	_, _, err = s.Reassign(ctx, prID, oldUserID)
 	return err
 }
 
 // GetStats прокидывает запрос статистики в репозиторий.
-func (s *PullRequestService) GetStats(ctx context.Context) (repository.Stats, error) {
-	return s.prs.GetStats(ctx)
+func (s *PullRequestService) GetStats(ctx context.Context, f repository.StatsFilter) (_ repository.Stats, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetStats", attribute.String("team.name", f.TeamName))
+	defer func() { tracing.End(span, err) }()
+
+	return s.prs.GetStats(ctx, f)
+}
+
+// GetMergeLatency возвращает перцентили времени от создания до merge PR.
+func (s *PullRequestService) GetMergeLatency(ctx context.Context, f repository.LatencyFilter) (_ repository.MergeLatency, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetMergeLatency")
+	defer func() { tracing.End(span, err) }()
+
+	return s.prs.GetMergeLatency(ctx, f)
 }
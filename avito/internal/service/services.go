@@ -0,0 +1,54 @@
+package service
+
+import (
+	"avito/internal/events"
+	"avito/internal/repository"
+)
+
+// Repositories — репозитории, поверх которых собираются сервисы.
+type Repositories struct {
+	Teams        repository.TeamRepository
+	Users        repository.UserRepository
+	PullRequests repository.PullRequestRepository
+	APIKeys      repository.APIKeyRepository
+	Orgs         repository.OrgRepository
+	// Events — журнал событий PR; nil отключает его, поток событий
+	// и WebSocket-инбокс.
+	Events repository.EventRepository
+	Tx     repository.Transactor
+}
+
+// Services — сервисы приложения. Один набор разделяют HTTP- и gRPC-серверы.
+type Services struct {
+	Teams        *TeamService
+	Users        *UserService
+	PullRequests *PullRequestService
+	Auth         *AuthService
+	Orgs         *OrgService
+	// Events и Inbox — nil, если журнал событий не подключён.
+	Events *EventService
+	Inbox  *InboxService
+}
+
+// NewServices собирает сервисы и связывает их между собой. broker раздаёт
+// события из журнала r.Events подписчикам процесса.
+func NewServices(r Repositories, broker *events.Broker, prOpts []PullRequestOption, authOpts []AuthOption) *Services {
+	if r.Events != nil {
+		prOpts = append(prOpts, WithEventLog(r.Events))
+	}
+
+	s := &Services{
+		Teams:        NewTeamService(r.Teams, r.Users, r.PullRequests, r.APIKeys, r.Tx),
+		Users:        NewUserService(r.Users),
+		PullRequests: NewPullRequestService(r.PullRequests, r.Users, r.Teams, r.Tx, prOpts...),
+		Auth:         NewAuthService(r.APIKeys, r.Users, r.Teams, authOpts...),
+		Orgs:         NewOrgService(r.Orgs),
+	}
+	if r.Events != nil {
+		s.Events = NewEventService(r.Events, broker)
+		s.Inbox = NewInboxService(r.Users, r.PullRequests, broker)
+	}
+	// prSvc нужен teamSvc для BulkDeactivateTeam
+	s.Teams.SetPullRequestService(s.PullRequests)
+	return s
+}
@@ -1,25 +1,42 @@
 package service
 
 import (
+	"avito/internal/auth"
 	"avito/internal/domain"
 	"avito/internal/errs"
+	"avito/internal/logging"
+	"avito/internal/metrics"
 	"avito/internal/repository"
+	"avito/internal/tracing"
 	"context"
 	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TeamService struct {
 	teams repository.TeamRepository
 	users repository.UserRepository
 	prs   repository.PullRequestRepository
+	keys  repository.APIKeyRepository
+	tx    repository.Transactor
 	prSvc *PullRequestService
 }
 
-func NewTeamService(tr repository.TeamRepository, ur repository.UserRepository, pr repository.PullRequestRepository) *TeamService {
+func NewTeamService(
+	tr repository.TeamRepository,
+	ur repository.UserRepository,
+	pr repository.PullRequestRepository,
+	kr repository.APIKeyRepository,
+	tx repository.Transactor,
+) *TeamService {
 	return &TeamService{
 		teams: tr,
 		users: ur,
 		prs:   pr,
+		keys:  kr,
+		tx:    tx,
 	}
 }
 
@@ -27,13 +44,40 @@ func (s *TeamService) SetPullRequestService(prSvc *PullRequestService) {
 	s.prSvc = prSvc
 }
 
+// checkCanManage проверяет право на управление командой teamName. Лид
+// сверяется по ID команды, а не по имени, чтобы переименование не
+// лишало его прав; для этого команду приходится загрузить.
+func (s *TeamService) checkCanManage(ctx context.Context, teamName, msg string) error {
+	if auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil
+	}
+	if !auth.HasRole(ctx, domain.RoleTeamLead) {
+		return errForbidden(msg)
+	}
+	team, err := s.GetTeam(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !auth.CanManageTeam(ctx, team.ID) {
+		return errForbidden(msg)
+	}
+	return nil
+}
+
 type BulkDeactivateResult struct {
 	TeamName            string
 	DeactivatedUsers    int64
 	ReassignedReviewers int64
 }
 
-func (s *TeamService) BulkDeactivateTeam(ctx context.Context, teamName string) (*BulkDeactivateResult, error) {
+func (s *TeamService) BulkDeactivateTeam(ctx context.Context, teamName string) (_ *BulkDeactivateResult, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.BulkDeactivateTeam", attribute.String("team.name", teamName))
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.checkCanManage(ctx, teamName, "only admins or the team lead can deactivate team "+teamName); err != nil {
+		return nil, err
+	}
+
 	// получить всех открытых назначений для команды до деактивации
 	assignments, err := s.prs.GetOpenAssignmentsByTeam(ctx, teamName)
 	if err != nil {
@@ -54,7 +98,28 @@ func (s *TeamService) BulkDeactivateTeam(ctx context.Context, teamName string) (
 			reassigned++
 			continue
 		}
+		logging.FromContext(ctx).WarnContext(ctx, "reassign after team deactivation failed",
+			"team_name", teamName, "pr_id", a.PRID, "user_id", a.UserID, "error", err.Error())
+		span.AddEvent("reassign failed", trace.WithAttributes(
+			attribute.String("pr.id", a.PRID),
+			attribute.String("pr.old_reviewer_id", a.UserID),
+			attribute.String("error", err.Error()),
+		))
 	}
+	span.SetAttributes(
+		attribute.Int("assignments.open", len(assignments)),
+		attribute.Int64("users.deactivated", deactivated),
+		attribute.Int64("reviewers.reassigned", reassigned),
+	)
+
+	logging.FromContext(ctx).InfoContext(ctx, "team deactivated",
+		"team_name", teamName,
+		"deactivated_users", deactivated,
+		"reassigned_reviewers", reassigned,
+		"failed_reassignments", int64(len(assignments))-reassigned,
+	)
+	metrics.BulkDeactivations.Inc()
+	metrics.BulkDeactivatedUsers.Add(float64(deactivated))
 
 	return &BulkDeactivateResult{
 		TeamName:            teamName,
@@ -63,35 +128,62 @@ func (s *TeamService) BulkDeactivateTeam(ctx context.Context, teamName string) (
 	}, nil
 }
 
-func (s *TeamService) CreateTeam(team domain.Team) (*domain.Team, error) {
-	// Проверяем, что команда ещё не существует
-	if _, err := s.teams.GetTeam(team.TeamName); err == nil {
-		return nil, errs.New(errs.CodeTeamExists, "team_name already exists")
-	} else if !errors.Is(err, repository.ErrNotFound) {
-		return nil, err
+func (s *TeamService) CreateTeam(ctx context.Context, team domain.Team) (_ *domain.Team, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.CreateTeam",
+		attribute.String("team.name", team.TeamName),
+		attribute.Int("team.members", len(team.Members)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil, errForbidden("only admins can create teams")
 	}
 
-	if err := s.teams.CreateTeam(team); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Проверяем, что команда ещё не существует
+		if _, err := s.teams.GetTeam(ctx, team.TeamName); err == nil {
+			return errs.New(errs.CodeTeamExists, "team_name already exists")
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		if err := s.checkNotInOtherTeam(ctx, team.TeamName, team.Members); err != nil {
+			return err
+		}
+		// CreateTeam создаёт новых пользователей и обновляет имя
+		// и активность существующих
+		return s.teams.CreateTeam(ctx, team)
+	})
+	if err != nil {
 		return nil, err
 	}
+	return &team, nil
+}
 
-	for _, m := range team.Members {
-		u := domain.User{
-			ID:       m.UserID,
-			Username: m.Username,
-			TeamName: team.TeamName,
-			IsActive: m.IsActive,
+// checkNotInOtherTeam не даёт добавить в teamName пользователя, который
+// состоит в другой команде: переводить участников — дело MoveMember,
+// который заодно переназначает их ревью.
+func (s *TeamService) checkNotInOtherTeam(ctx context.Context, teamName string, members []domain.TeamMember) error {
+	for _, m := range members {
+		u, err := s.users.GetUser(ctx, m.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
 		}
-		if err := s.users.UpsertUser(u); err != nil {
-			return nil, err
+		if err != nil {
+			return err
+		}
+		if u.TeamName != "" && u.TeamName != teamName {
+			return errs.New(errs.CodeUserInOtherTeam, "user "+m.UserID+" is a member of team "+u.TeamName)
 		}
 	}
-
-	return &team, nil
+	return nil
 }
 
-func (s *TeamService) GetTeam(name string) (*domain.Team, error) {
-	team, err := s.teams.GetTeam(name)
+func (s *TeamService) GetTeam(ctx context.Context, name string) (_ *domain.Team, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.GetTeam", attribute.String("team.name", name))
+	defer func() { tracing.End(span, err) }()
+
+	team, err := s.teams.GetTeam(ctx, name)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errs.New(errs.CodeNotFound, "team not found")
@@ -100,3 +192,350 @@ func (s *TeamService) GetTeam(name string) (*domain.Team, error) {
 	}
 	return team, nil
 }
+
+// GetTeams возвращает команды с участниками по списку имён одним запросом;
+// несуществующие команды в результат не попадают.
+func (s *TeamService) GetTeams(ctx context.Context, names []string) (_ []domain.Team, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.GetTeams", attribute.Int("team.count", len(names)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.teams.GetTeams(ctx, names)
+}
+
+// AddMembers добавляет участников в существующую команду. Пользователь,
+// уже состоящий в другой команде, не добавляется: для этого есть MoveMember.
+func (s *TeamService) AddMembers(ctx context.Context, teamName string, members []domain.TeamMember) (_ *domain.Team, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.AddMembers",
+		attribute.String("team.name", teamName),
+		attribute.Int("team.members", len(members)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.checkCanManage(ctx, teamName, "only admins or the team lead can change team "+teamName); err != nil {
+		return nil, err
+	}
+
+	var team *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.GetTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		if err := s.checkNotInOtherTeam(ctx, teamName, members); err != nil {
+			return err
+		}
+
+		if err := s.teams.AddMembers(ctx, teamName, members); err != nil {
+			return err
+		}
+
+		var err error
+		team, err = s.GetTeam(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+type MembershipChangeResult struct {
+	TeamName            string
+	ChangedUsers        int64
+	ReassignedReviewers int64
+	// UnresolvedReviews — открытые ревью, для которых не нашлось замены;
+	// они остаются за прежним ревьювером.
+	UnresolvedReviews int64
+}
+
+// RemoveMembers выводит пользователей из команды. Если reassign включён,
+// их открытые ревью переназначаются на участников команды автора PR.
+func (s *TeamService) RemoveMembers(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	reassign bool,
+) (_ *MembershipChangeResult, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.RemoveMembers",
+		attribute.String("team.name", teamName),
+		attribute.StringSlice("user.ids", userIDs),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.checkCanManage(ctx, teamName, "only admins or the team lead can change team "+teamName); err != nil {
+		return nil, err
+	}
+
+	res := &MembershipChangeResult{TeamName: teamName}
+	var done []*reassignment
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		team, err := s.GetTeam(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		members := make(map[string]struct{}, len(team.Members))
+		for _, m := range team.Members {
+			members[m.UserID] = struct{}{}
+		}
+		for _, id := range userIDs {
+			if _, ok := members[id]; !ok {
+				return errs.New(errs.CodeNotFound, "user "+id+" is not a member of team "+teamName)
+			}
+		}
+
+		res.ChangedUsers, err = s.teams.RemoveMembers(ctx, teamName, userIDs)
+		if err != nil {
+			return err
+		}
+
+		if !reassign {
+			return nil
+		}
+		for _, id := range userIDs {
+			reassigned, err := s.reassignForeignReviews(ctx, id, "", res)
+			if err != nil {
+				return err
+			}
+			done = append(done, reassigned...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range done {
+		s.prSvc.logReassigned(ctx, r)
+	}
+	span.SetAttributes(
+		attribute.Int64("users.changed", res.ChangedUsers),
+		attribute.Int64("reviewers.reassigned", res.ReassignedReviewers),
+		attribute.Int64("reviews.unresolved", res.UnresolvedReviews),
+	)
+	return res, nil
+}
+
+// MoveMember переводит пользователя в другую команду. Если reassign включён,
+// его открытые ревью у авторов из других команд переназначаются,
+// чтобы не нарушать правило «ревьюверы из команды автора».
+func (s *TeamService) MoveMember(
+	ctx context.Context,
+	userID string,
+	toTeamName string,
+	reassign bool,
+) (_ *MembershipChangeResult, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.MoveMember",
+		attribute.String("user.id", userID),
+		attribute.String("team.name", toTeamName),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil, errForbidden("only admins can move users between teams")
+	}
+
+	res := &MembershipChangeResult{TeamName: toTeamName}
+	var done []*reassignment
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		u, err := s.users.GetUser(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errs.New(errs.CodeNotFound, "user not found")
+			}
+			return err
+		}
+		if _, err := s.GetTeam(ctx, toTeamName); err != nil {
+			return err
+		}
+		if u.TeamName == toTeamName {
+			return nil
+		}
+
+		if err := s.teams.MoveMember(ctx, userID, toTeamName); err != nil {
+			return err
+		}
+		res.ChangedUsers = 1
+
+		if !reassign {
+			return nil
+		}
+		done, err = s.reassignForeignReviews(ctx, userID, toTeamName, res)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range done {
+		s.prSvc.logReassigned(ctx, r)
+	}
+	span.SetAttributes(
+		attribute.Int64("users.changed", res.ChangedUsers),
+		attribute.Int64("reviewers.reassigned", res.ReassignedReviewers),
+		attribute.Int64("reviews.unresolved", res.UnresolvedReviews),
+	)
+	return res, nil
+}
+
+// reassignForeignReviews переназначает открытые ревью userID на PR, автор
+// которых не состоит в команде userTeam, на участника команды автора.
+// Вызывается внутри транзакции и возвращает сделанные замены: в лог
+// и метрики они попадают после её фиксации.
+func (s *TeamService) reassignForeignReviews(
+	ctx context.Context,
+	userID string,
+	userTeam string,
+	res *MembershipChangeResult,
+) (_ []*reassignment, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.reassignForeignReviews",
+		attribute.String("user.id", userID),
+		attribute.String("team.name", userTeam),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	prs, err := s.prs.GetOpenPRsByReviewer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*reassignment
+	for i := range prs {
+		pr := &prs[i]
+		author, err := s.users.GetUser(ctx, pr.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if author.TeamName == userTeam {
+			continue
+		}
+
+		r, err := s.prSvc.reassignWithinTeam(ctx, pr.ID, userID, author.TeamName)
+		var appErr *errs.AppError
+		switch {
+		case err == nil:
+			res.ReassignedReviewers++
+			done = append(done, r)
+		case errors.As(err, &appErr) &&
+			(appErr.Code == errs.CodePRMerged || appErr.Code == errs.CodeNotAssigned):
+			// PR смержили или ревьювера заменили параллельно: менять нечего
+		case errors.As(err, &appErr) &&
+			(appErr.Code == errs.CodeNoCandidate || appErr.Code == errs.CodeNotFound):
+			logging.FromContext(ctx).WarnContext(ctx, "review left with reviewer outside author team",
+				"pr_id", pr.ID, "user_id", userID, "team_name", author.TeamName, "error_code", string(appErr.Code))
+			res.UnresolvedReviews++
+		default:
+			return nil, err
+		}
+	}
+	return done, nil
+}
+
+// RenameTeam переименовывает команду; участники остаются привязаны к ней.
+func (s *TeamService) RenameTeam(ctx context.Context, oldName, newName string) (_ *domain.Team, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.RenameTeam",
+		attribute.String("team.name", oldName),
+		attribute.String("team.new_name", newName),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.checkCanManage(ctx, oldName, "only admins or the team lead can rename team "+oldName); err != nil {
+		return nil, err
+	}
+
+	var team *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.teams.RenameTeam(ctx, oldName, newName)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return errs.New(errs.CodeNotFound, "team not found")
+		case errors.Is(err, repository.ErrAlreadyExists):
+			return errs.New(errs.CodeTeamExists, "team_name already exists")
+		case err != nil:
+			return err
+		}
+
+		team, err = s.GetTeam(ctx, newName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+type DeleteTeamResult struct {
+	TeamName    string
+	MovedUsers  int64
+	MovedToTeam string
+	// RevokedKeys — ключи team-lead команды, отозванные при удалении.
+	RevokedKeys int64
+}
+
+// DeleteTeam удаляет команду. Если в ней остались участники, нужно явно
+// указать команду moveMembersTo, куда они будут переведены; иначе
+// возвращается TEAM_NOT_EMPTY. Участники переводятся все вместе, поэтому
+// их открытые ревью не нарушают правило «ревьюверы из команды автора».
+// Ключи team-lead команды отзываются в той же транзакции.
+func (s *TeamService) DeleteTeam(ctx context.Context, name, moveMembersTo string) (_ *DeleteTeamResult, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.DeleteTeam",
+		attribute.String("team.name", name),
+		attribute.String("team.move_members_to", moveMembersTo),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if !auth.HasRole(ctx, domain.RoleAdmin) {
+		return nil, errForbidden("only admins can delete teams")
+	}
+
+	res := &DeleteTeamResult{TeamName: name}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		team, err := s.GetTeam(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		if len(team.Members) > 0 {
+			if moveMembersTo == "" {
+				return errs.New(errs.CodeTeamNotEmpty, "team has members; move_members_to is required")
+			}
+			if moveMembersTo == name {
+				return errs.Invalid("move_members_to", "must differ from team_name")
+			}
+			if _, err := s.GetTeam(ctx, moveMembersTo); err != nil {
+				return err
+			}
+
+			res.MovedUsers, err = s.teams.MoveAllMembers(ctx, name, moveMembersTo)
+			if err != nil {
+				return err
+			}
+			res.MovedToTeam = moveMembersTo
+		}
+
+		res.RevokedKeys, err = s.keys.RevokeTeamKeys(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		err = s.teams.DeleteTeam(ctx, name)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errs.New(errs.CodeNotFound, "team not found")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListTeams возвращает страницу команд, отсортированных по имени.
+func (s *TeamService) ListTeams(ctx context.Context, f repository.TeamFilter) (_ repository.TeamPage, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.ListTeams")
+	defer func() { tracing.End(span, err) }()
+
+	page, err := s.teams.ListTeams(ctx, f)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return page, errs.New(errs.CodeInvalidCursor, "cursor is invalid or was issued for another sort")
+	}
+	return page, err
+}
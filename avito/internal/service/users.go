@@ -1,10 +1,15 @@
 package service
 
 import (
+	"avito/internal/auth"
 	"avito/internal/domain"
 	"avito/internal/errs"
 	"avito/internal/repository"
+	"avito/internal/tracing"
+	"context"
 	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type UserService struct {
@@ -15,8 +20,27 @@ func NewUserService(ur repository.UserRepository) *UserService {
 	return &UserService{users: ur}
 }
 
-func (s *UserService) SetIsActive(userID string, active bool) (*domain.User, error) {
-	u, err := s.users.SetUserActive(userID, active)
+func (s *UserService) SetIsActive(ctx context.Context, userID string, active bool) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetIsActive",
+		attribute.String("user.id", userID),
+		attribute.Bool("user.is_active", active),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if p, ok := auth.FromContext(ctx); ok && p.Role != domain.RoleAdmin {
+		u, err := s.users.GetUser(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, errs.New(errs.CodeNotFound, "user not found")
+			}
+			return nil, err
+		}
+		if !auth.CanSetUserActive(ctx, userID, u.TeamID) {
+			return nil, errForbidden("not allowed to change is_active of user " + userID)
+		}
+	}
+
+	u, err := s.users.SetUserActive(ctx, userID, active)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errs.New(errs.CodeNotFound, "user not found")
@@ -26,8 +50,11 @@ func (s *UserService) SetIsActive(userID string, active bool) (*domain.User, err
 	return u, nil
 }
 
-func (s *UserService) GetUser(userID string) (*domain.User, error) {
-	u, err := s.users.GetUser(userID)
+func (s *UserService) GetUser(ctx context.Context, userID string) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser", attribute.String("user.id", userID))
+	defer func() { tracing.End(span, err) }()
+
+	u, err := s.users.GetUser(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errs.New(errs.CodeNotFound, "user not found")
@@ -36,3 +63,29 @@ func (s *UserService) GetUser(userID string) (*domain.User, error) {
 	}
 	return u, nil
 }
+
+// GetUsers возвращает пользователей по списку ID одним запросом;
+// несуществующие в результат не попадают.
+func (s *UserService) GetUsers(ctx context.Context, userIDs []string) (_ []domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsers", attribute.Int("user.count", len(userIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.users.GetUsers(ctx, userIDs)
+}
+
+// ListUsers возвращает страницу пользователей по фильтру.
+func (s *UserService) ListUsers(ctx context.Context, f repository.UserFilter) (_ repository.UserPage, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ListUsers", attribute.String("team.name", f.TeamName))
+	defer func() { tracing.End(span, err) }()
+
+	page, err := s.users.ListUsers(ctx, f)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return page, errs.New(errs.CodeInvalidCursor, "cursor is invalid or was issued for another sort")
+	}
+	return page, err
+}
+
+// errForbidden — отказ по правилам доступа из пакета auth.
+func errForbidden(msg string) error {
+	return errs.New(errs.CodeForbidden, msg)
+}
@@ -0,0 +1,39 @@
+// Package tenant хранит в контексте организацию, от имени которой
+// выполняется запрос. Репозитории фильтруют по ней все запросы.
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default — организация, в которую попадают запросы без явного тенанта
+// и данные, созданные до появления организаций.
+const Default = "default"
+
+// Header — заголовок, которым клиент без привязки к организации (bootstrap-
+// администратор или локальный запуск без аутентификации) выбирает тенанта.
+const Header = "X-Org-ID"
+
+// idPattern совпадает с CHECK на organizations.id.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ValidID сообщает, допустим ли id как идентификатор организации.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type orgKey struct{}
+
+// WithOrg кладёт организацию в контекст.
+func WithOrg(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// FromContext возвращает организацию запроса или Default, если она не задана.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(orgKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}